@@ -2,3 +2,11 @@
 
 // Package api provides APIs for syncing a chart repository
 package api
+
+import _ "embed"
+
+// ConfigProto is the proto definition of the config file. Its comments
+// document the config file schema.
+//
+//go:embed config.proto
+var ConfigProto string
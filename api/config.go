@@ -1,49 +1,357 @@
 package api
 
 import (
+	goerrors "errors"
+	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/pkg/errors"
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
 )
 
-// Validate validates the config file is correct
+// Validate validates the config file is correct. All the problems found are
+// returned at once, joined in a single error.
 func (c *Config) Validate() error {
-	if repo := c.GetSource().GetRepo(); repo != nil {
-		switch k := repo.GetKind(); k {
-		case Kind_CHARTMUSEUM, Kind_HELM, Kind_HARBOR, Kind_OCI:
-			if _, err := url.ParseRequestURI(repo.GetUrl()); err != nil {
-				return errors.Errorf(`"source.repo.url" should be a valid URL: %v`, err)
+	var errs error
+	if c.GetSource() != nil {
+		errs = goerrors.Join(errs, c.GetSource().validate("source"))
+	}
+	for i, s := range c.GetSources() {
+		errs = goerrors.Join(errs, s.validate(fmt.Sprintf("sources[%d]", i)))
+	}
+	if c.GetConflictPolicy() == ConflictPolicy_PREFIX {
+		// The charts of the first source are never renamed
+		for i, s := range c.AllSources() {
+			if i > 0 && s.GetPrefix() == "" {
+				errs = goerrors.Join(errs, newFieldError("conflictPolicy", `all sources but the first one require a "prefix" when "conflictPolicy" is "PREFIX"`))
+				break
 			}
 		}
 	}
-	if repo := c.GetTarget().GetRepo(); repo != nil {
+
+	if len(c.GetCharts()) > 0 && len(c.GetSkipCharts()) > 0 {
+		errs = goerrors.Join(errs, newFieldError("skipCharts", `"charts" and "skipCharts" properties can not be set at the same time`))
+	}
+
+	for i, m := range c.GetChartMappings() {
+		errs = goerrors.Join(errs, m.validate(fmt.Sprintf("chartMappings[%d]", i)))
+	}
+
+	if v := c.GetVerification(); v != nil && v.GetKeyring() == "" && v.GetPublicKey() == "" {
+		errs = goerrors.Join(errs, newFieldError("verification", `"verification" requires a "keyring" or a "publicKey"`))
+	}
+	if s := c.GetScan(); s != nil && len(s.GetCommand()) == 0 {
+		errs = goerrors.Join(errs, newFieldError("scan.command", `"scan.command" is required`))
+	}
+	if t := c.GetTransform(); t != nil {
+		errs = goerrors.Join(errs, t.validate("transform"))
+	}
+	if b := c.GetBandwidth(); b != nil {
+		errs = goerrors.Join(errs, b.validate("bandwidth"))
+	}
+	if r := c.GetRateLimit(); r != nil {
+		errs = goerrors.Join(errs, r.validate("rateLimit"))
+	}
+	for i, h := range c.GetHooks() {
+		if len(h.GetCommand()) == 0 {
+			field := fmt.Sprintf("hooks[%d].command", i)
+			errs = goerrors.Join(errs, newFieldError(field, `"%s" is required`, field))
+		}
+	}
+
+	if c.GetTarget() != nil {
+		errs = goerrors.Join(errs, c.GetTarget().validate("target"))
+	}
+	for i, t := range c.GetTargets() {
+		errs = goerrors.Join(errs, t.validate(fmt.Sprintf("targets[%d]", i)))
+	}
+
+	return errs
+}
+
+// FieldError is a validation error about a specific field of the config file
+type FieldError struct {
+	// Field is the path of the field in the config file, i.e "targets[1].repo.url"
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// newFieldError returns a FieldError for field with the provided message
+func newFieldError(field, format string, args ...interface{}) error {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// AllSources returns the list of sources to sync from, that is, the "source"
+// property followed by the entries of the "sources" one
+func (c *Config) AllSources() []*Source {
+	var sources []*Source
+	if c.GetSource() != nil {
+		sources = append(sources, c.GetSource())
+	}
+	return append(sources, c.GetSources()...)
+}
+
+// AllTargets returns the list of targets to sync into, that is, the "target"
+// property followed by the entries of the "targets" one
+func (c *Config) AllTargets() []*Target {
+	var targets []*Target
+	if c.GetTarget() != nil {
+		targets = append(targets, c.GetTarget())
+	}
+	return append(targets, c.GetTargets()...)
+}
+
+// validate validates a source. The field argument is the path of the source
+// in the config file, and it is used to compose meaningful error messages.
+func (s *Source) validate(field string) error {
+	var errs error
+	if repo := s.GetRepo(); repo != nil {
 		switch k := repo.GetKind(); k {
 		case Kind_CHARTMUSEUM, Kind_HELM, Kind_HARBOR, Kind_OCI:
 			if _, err := url.ParseRequestURI(repo.GetUrl()); err != nil {
-				return errors.Errorf(`"target.repo.url" should be a valid URL: %v`, err)
+				errs = goerrors.Join(errs, newFieldError(field+".repo.url", `"%s.repo.url" should be a valid URL: %v`, field, err))
 			}
 		}
 	}
-
-	// Authentication
-	// Container images
-	if auth := c.GetSource().GetContainers().GetAuth(); auth != nil {
+	if auth := s.GetContainers().GetAuth(); auth != nil {
 		if auth.Username == "" || auth.Password == "" || auth.Registry == "" {
-			return errors.Errorf(`"source.containers.auth" "registry", "username"" and "password" are required"`)
+			errs = goerrors.Join(errs, newFieldError(field+".containers.auth", `"%s.containers.auth" "registry", "username"" and "password" are required"`, field))
+		}
+	}
+	return errs
+}
+
+// validate validates a target. The field argument is the path of the target
+// in the config file, and it is used to compose meaningful error messages.
+func (t *Target) validate(field string) error {
+	var errs error
+	if repo := t.GetRepo(); repo != nil {
+		switch k := repo.GetKind(); k {
+		case Kind_CHARTMUSEUM, Kind_HELM, Kind_HARBOR, Kind_OCI:
+			if _, err := url.ParseRequestURI(repo.GetUrl()); err != nil {
+				errs = goerrors.Join(errs, newFieldError(field+".repo.url", `"%s.repo.url" should be a valid URL: %v`, field, err))
+			}
 		}
 	}
-	if auth := c.GetTarget().GetContainers().GetAuth(); auth != nil {
+	if auth := t.GetContainers().GetAuth(); auth != nil {
 		// NOTE: we do not indicate that the registry is empty because this one is set from target.containerRegistry
 		// so the user does not need to set it up
 		if auth.Username == "" || auth.Password == "" {
-			return errors.Errorf(`"target.containers.auth" "username"" and "password" are required"`)
+			errs = goerrors.Join(errs, newFieldError(field+".containers.auth", `"%s.containers.auth" "username"" and "password" are required"`, field))
 		}
 	}
-	if repo := c.GetTarget().GetRepo(); repo != nil {
+	for i, m := range t.GetContainers().GetImageMappings() {
+		errs = goerrors.Join(errs, m.validate(fmt.Sprintf("%s.containers.imageMappings[%d]", field, i)))
+	}
+	// LOCAL targets store the charts wrapped, without relocating their images
+	if len(t.GetContainers().GetImageMappings()) > 0 && t.GetRepo().GetKind() == Kind_LOCAL {
+		errs = goerrors.Join(errs, newFieldError(field+".containers.imageMappings", `"%s.containers.imageMappings" is only supported by OCI targets`, field))
+	}
+	if repo := t.GetRepo(); repo != nil {
 		if repo.GetKind() != Kind_OCI && repo.GetKind() != Kind_LOCAL {
-			return errors.Errorf(`"target.repo.kind" should be "OCI" or "LOCAL"`)
+			errs = goerrors.Join(errs, newFieldError(field+".repo.kind", `"%s.repo.kind" should be "OCI" or "LOCAL"`, field))
+		}
+	}
+	if t.GetPublishChartsIndex() && t.GetRepo().GetKind() != Kind_OCI {
+		errs = goerrors.Join(errs, newFieldError(field+".publishChartsIndex", `"%s.publishChartsIndex" is only supported by OCI targets`, field))
+	}
+	// Charts indexes are published as OCI artifacts, optionally with the oci:// scheme
+	if t.GetPublishChartsIndex() && strings.Contains(strings.TrimPrefix(t.GetRepo().GetChartsIndex(), "oci://"), "://") {
+		errs = goerrors.Join(errs, newFieldError(field+".repo.chartsIndex", `"%s.repo.chartsIndex" should be an OCI reference to publish the charts index`, field))
+	}
+	if sig := t.GetSigning(); sig != nil {
+		if t.GetRepo().GetKind() != Kind_OCI {
+			errs = goerrors.Join(errs, newFieldError(field+".signing", `"%s.signing" is only supported by OCI targets`, field))
+		}
+		if sig.GetPrivateKey() == "" {
+			errs = goerrors.Join(errs, newFieldError(field+".signing.privateKey", `"%s.signing.privateKey" is required`, field))
+		}
+		if sig.GetSignChartsIndex() && !t.GetPublishChartsIndex() {
+			errs = goerrors.Join(errs, newFieldError(field+".signing.signChartsIndex", `"%s.signing.signChartsIndex" requires "publishChartsIndex"`, field))
 		}
 	}
+	return errs
+}
+
+// validate validates a chart mapping rule. The field argument is the path of
+// the rule in the config file, and it is used to compose meaningful error
+// messages.
+func (m *ChartMapping) validate(field string) error {
+	var errs error
+	for i, pattern := range m.GetCharts() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = goerrors.Join(errs, newFieldError(fmt.Sprintf("%s.charts[%d]", field, i), `"%s.charts" contains an invalid pattern %q: %v`, field, pattern, err))
+		}
+	}
+	if m.GetName() != "" {
+		if len(m.GetCharts()) != 1 || strings.ContainsAny(m.GetCharts()[0], `*?[\\`) {
+			errs = goerrors.Join(errs, newFieldError(field+".name", `"%s.name" requires "charts" to contain a single chart name`, field))
+		}
+	}
+	if p := m.GetPath(); p != "" {
+		if path.IsAbs(p) || path.Clean(p) != p || strings.HasPrefix(p, "..") {
+			errs = goerrors.Join(errs, newFieldError(field+".path", `"%s.path" should be a relative path like "db" or "apps/web"`, field))
+		}
+	}
+	return errs
+}
+
+// validate validates the chart transform. The field argument is the path of
+// the transform in the config file, and it is used to compose meaningful error
+// messages.
+func (t *Transform) validate(field string) error {
+	var errs error
+	for i, v := range t.GetValues() {
+		f := fmt.Sprintf("%s.values[%d]", field, i)
+		if v.GetPath() == "" {
+			errs = goerrors.Join(errs, newFieldError(f+".path", `"%s.path" is required`, f))
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(v.GetValue()), &value); err != nil {
+			errs = goerrors.Join(errs, newFieldError(f+".value", `"%s.value" should be a YAML encoded value: %v`, f, err))
+		}
+	}
+	if s := t.GetVersionSuffix(); s != "" {
+		if _, err := semver.StrictNewVersion("1.0.0" + s); err != nil || !strings.HasPrefix(s, "-") && !strings.HasPrefix(s, "+") {
+			errs = goerrors.Join(errs, newFieldError(field+".versionSuffix", `"%s.versionSuffix" should be a semver pre-release or build suffix like "-mirror" or "+mirror"`, field))
+		}
+	}
+	return errs
+}
+
+// validate validates the bandwidth limits. The field argument is the path of
+// the limits in the config file, and it is used to compose meaningful error
+// messages.
+func (b *Bandwidth) validate(field string) error {
+	var errs error
+	if _, err := ParseBandwidth(b.GetMax()); b.GetMax() != "" && err != nil {
+		errs = goerrors.Join(errs, newFieldError(field+".max", `"%s.max" %v`, field, err))
+	}
+	for i, r := range b.GetRegistries() {
+		f := fmt.Sprintf("%s.registries[%d]", field, i)
+		if r.GetRegistry() == "" || strings.Contains(r.GetRegistry(), "/") {
+			errs = goerrors.Join(errs, newFieldError(f+".registry", `"%s.registry" should be a registry host like "docker.io"`, f))
+		}
+		if _, err := ParseBandwidth(r.GetMax()); err != nil {
+			errs = goerrors.Join(errs, newFieldError(f+".max", `"%s.max" %v`, f, err))
+		}
+	}
+	return errs
+}
+
+// validate validates the request rate limits. The field argument is the path
+// of the limits in the config file, and it is used to compose meaningful error
+// messages.
+func (r *RateLimit) validate(field string) error {
+	var errs error
+	if r.GetRequestsPerSecond() < 0 {
+		errs = goerrors.Join(errs, newFieldError(field+".requestsPerSecond", `"%s.requestsPerSecond" should not be negative`, field))
+	}
+	for i, l := range r.GetRegistries() {
+		f := fmt.Sprintf("%s.registries[%d]", field, i)
+		if l.GetRegistry() == "" || strings.Contains(l.GetRegistry(), "/") {
+			errs = goerrors.Join(errs, newFieldError(f+".registry", `"%s.registry" should be a registry host like "docker.io"`, f))
+		}
+		if l.GetRequestsPerSecond() <= 0 {
+			errs = goerrors.Join(errs, newFieldError(f+".requestsPerSecond", `"%s.requestsPerSecond" should be positive`, f))
+		}
+	}
+	if w := r.GetMaxRetryWait(); w != "" {
+		if d, err := time.ParseDuration(w); err != nil || d < 0 {
+			errs = goerrors.Join(errs, newFieldError(field+".maxRetryWait", `"%s.maxRetryWait" should be a duration like "30s" or "5m"`, field))
+		}
+	}
+	return errs
+}
+
+// bandwidthRe matches bandwidths like "512KiB", "10MB/s" or "1G"
+var bandwidthRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(?:([kKMG])(i)?)?B?(?:/s)?$`)
+
+// ParseBandwidth returns the bytes per second of a bandwidth like "512KiB",
+// "10MB/s" or "1G". Units are powers of 1000, or of 1024 with an "i" suffix.
+func ParseBandwidth(s string) (int64, error) {
+	m := bandwidthRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("should be a bandwidth like \"512KiB\" or \"10MB\", got %q", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("should be a bandwidth like \"512KiB\" or \"10MB\", got %q", s)
+	}
+	base := 1000.0
+	if m[3] != "" {
+		base = 1024
+	}
+	switch strings.ToUpper(m[2]) {
+	case "K":
+		n *= base
+	case "M":
+		n *= base * base
+	case "G":
+		n *= base * base * base
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("should be at least 1 byte per second, got %q", s)
+	}
+	return int64(n), nil
+}
+
+// Matches returns whether the rule applies to the chart with the provided name
+func (m *ChartMapping) Matches(name string) bool {
+	if len(m.GetCharts()) == 0 {
+		return true
+	}
+	for _, pattern := range m.GetCharts() {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
 
+// validate validates an image mapping rule. The field argument is the path of
+// the rule in the config file, and it is used to compose meaningful error
+// messages.
+func (m *ImageMapping) validate(field string) error {
+	from, to := m.GetFrom(), m.GetTo()
+	if from == "" || to == "" {
+		return newFieldError(field, `"%s" "from" and "to" are required`, field)
+	}
+	if strings.Contains(strings.TrimSuffix(from, "*"), "*") || strings.Contains(strings.TrimSuffix(to, "*"), "*") {
+		return newFieldError(field, `"%s" only supports a trailing "*"`, field)
+	}
+	if strings.HasSuffix(to, "*") && !strings.HasSuffix(from, "*") {
+		return newFieldError(field+".to", `"%s.to" can only end with "*" if "from" does too`, field)
+	}
 	return nil
 }
+
+// Map returns the target repository for the provided source image repository,
+// and whether the rule applies to it. Repositories should include the
+// registry, i.e "docker.io/bitnami/kafka".
+func (m *ImageMapping) Map(repository string) (string, bool) {
+	from, to := m.GetFrom(), m.GetTo()
+	if !strings.HasSuffix(from, "*") {
+		if repository != from {
+			return "", false
+		}
+		return to, true
+	}
+	rest, ok := strings.CutPrefix(repository, strings.TrimSuffix(from, "*"))
+	if !ok || rest == "" {
+		return "", false
+	}
+	if !strings.HasSuffix(to, "*") {
+		return to, true
+	}
+	return strings.TrimSuffix(to, "*") + rest, true
+}
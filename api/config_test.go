@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/bitnami/charts-syncer/api"
@@ -33,9 +34,457 @@ func TestValidate(t *testing.T) {
 	if err := config.Validate(); err == nil {
 		t.Errorf("expected error but got nothing")
 	} else {
-		expectedError := `"source.repo.url" should be a valid URL: parse "ht//:fake.source.com": invalid URI for request`
+		// All the problems are reported at once
+		expectedError := `"source.repo.url" should be a valid URL: parse "ht//:fake.source.com": invalid URI for request` + "\n" +
+			`"target.repo.kind" should be "OCI" or "LOCAL"`
 		if err.Error() != expectedError {
 			t.Errorf("incorrect error, got: \n %s \n, want: \n %s \n", err.Error(), expectedError)
 		}
 	}
 }
+
+func TestValidateTargets(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{
+				Url:  "http://fake.source.com",
+				Kind: api.Kind_HELM,
+			},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{
+				Url:  "http://fake.target.com",
+				Kind: api.Kind_OCI,
+			},
+		},
+		Targets: []*api.Target{
+			{
+				Repo: &api.Repo{
+					Kind: api.Kind_LOCAL,
+					Path: "/tmp/charts",
+				},
+			},
+			{
+				Repo: &api.Repo{
+					Url:  "http://fake.target.com",
+					Kind: api.Kind_HELM,
+				},
+			},
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("expected error but got nothing")
+	} else {
+		expectedError := `"targets[1].repo.kind" should be "OCI" or "LOCAL"`
+		if err.Error() != expectedError {
+			t.Errorf("incorrect error, got: \n %s \n, want: \n %s \n", err.Error(), expectedError)
+		}
+	}
+	if got := len(config.AllTargets()); got != 3 {
+		t.Errorf("got %d targets, want 3", got)
+	}
+}
+
+func TestValidatePublishChartsIndex(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo:               &api.Repo{Path: "/tmp/charts", Kind: api.Kind_LOCAL},
+			PublishChartsIndex: true,
+		},
+	}
+
+	expectedError := `"target.publishChartsIndex" is only supported by OCI targets`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+
+	config.Target.Repo = &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	config.Target.Repo.ChartsIndex = "oci://fake.target.com/charts-index:latest"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	config.Target.Repo.ChartsIndex = "file://index.yaml"
+	expectedError = `"target.repo.chartsIndex" should be an OCI reference to publish the charts index`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+}
+
+func TestValidateVerification(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		Verification: &api.Verification{Policy: api.VerificationPolicy_FLAG},
+	}
+
+	expectedError := `"verification" requires a "keyring" or a "publicKey"`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+
+	config.Verification.Keyring = "/home/user/.gnupg/pubring.gpg"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateScan(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		Scan: &api.Scan{Severity: api.Severity_HIGH},
+	}
+
+	expectedError := `"scan.command" is required`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+
+	config.Scan.Command = []string{"/usr/local/bin/scan-chart"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		Hooks: []*api.Hook{
+			{Command: []string{"/usr/local/bin/open-ticket"}},
+			{Stages: []api.HookStage{api.HookStage_END_OF_RUN}},
+		},
+	}
+
+	expectedError := `"hooks[1].command" is required`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+}
+
+func TestValidateTransform(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		Transform: &api.Transform{
+			Values: []*api.ValuePatch{
+				{Path: "metrics.enabled", Value: "true"},
+				{Value: "1"},
+				{Path: "tolerations", Value: "[unclosed"},
+			},
+			VersionSuffix: "mirror",
+		},
+	}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{
+		`"transform.values[1].path" is required`,
+		`"transform.values[2].value" should be a YAML encoded value`,
+		`"transform.versionSuffix" should be a semver pre-release or build suffix like "-mirror" or "+mirror"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "values[0]") {
+		t.Errorf("got error %q, want no error about valid values", err)
+	}
+}
+
+func TestValidateBandwidth(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		Bandwidth: &api.Bandwidth{
+			Max: "10 parsecs",
+			Registries: []*api.RegistryBandwidth{
+				{Registry: "docker.io", Max: "1MiB"},
+				{Registry: "https://quay.io", Max: "0"},
+			},
+		},
+	}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{
+		`"bandwidth.max" should be a bandwidth like "512KiB" or "10MB", got "10 parsecs"`,
+		`"bandwidth.registries[1].registry" should be a registry host like "docker.io"`,
+		`"bandwidth.registries[1].max" should be at least 1 byte per second, got "0"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "registries[0]") {
+		t.Errorf("got error %q, want no error about valid registries", err)
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		RateLimit: &api.RateLimit{
+			RequestsPerSecond: -1,
+			Registries: []*api.RegistryRateLimit{
+				{Registry: "docker.io", RequestsPerSecond: 0.5},
+				{Registry: "quay.io"},
+			},
+			MaxRetryWait: "1 minute",
+		},
+	}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{
+		`"rateLimit.requestsPerSecond" should not be negative`,
+		`"rateLimit.registries[1].requestsPerSecond" should be positive`,
+		`"rateLimit.maxRetryWait" should be a duration like "30s" or "5m"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "registries[0]") {
+		t.Errorf("got error %q, want no error about valid registries", err)
+	}
+}
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		bandwidth string
+		want      int64
+		wantErr   bool
+	}{
+		{bandwidth: "1024", want: 1024},
+		{bandwidth: "512KiB", want: 512 * 1024},
+		{bandwidth: "10MB/s", want: 10 * 1000 * 1000},
+		{bandwidth: "1.5G", want: 1500 * 1000 * 1000},
+		{bandwidth: "2 Mi", want: 2 * 1024 * 1024},
+		{bandwidth: "10Mbps", wantErr: true},
+		{bandwidth: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := api.ParseBandwidth(tc.bandwidth)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseBandwidth(%q) error = %v, wantErr %v", tc.bandwidth, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseBandwidth(%q) = %d, want %d", tc.bandwidth, got, tc.want)
+		}
+	}
+}
+
+func TestValidateSourcesPrefix(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{
+				Url:  "https://charts.bitnami.com/bitnami",
+				Kind: api.Kind_HELM,
+			},
+		},
+		Sources: []*api.Source{
+			{
+				Repo: &api.Repo{
+					Url:  "https://charts.internal.com",
+					Kind: api.Kind_HELM,
+				},
+			},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{
+				Url:  "http://fake.target.com",
+				Kind: api.Kind_OCI,
+			},
+		},
+		ConflictPolicy: api.ConflictPolicy_PREFIX,
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Errorf("expected error but got nothing")
+	}
+
+	config.Sources[0].Prefix = "internal-"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateChartMappings(t *testing.T) {
+	tests := []struct {
+		desc    string
+		mapping *api.ChartMapping
+		wantErr string
+	}{
+		{
+			desc:    "prefix and path for a group of charts",
+			mapping: &api.ChartMapping{Charts: []string{"mariadb*", "postgresql"}, Prefix: "bitnami-", Path: "db"},
+		},
+		{
+			desc:    "rename a single chart",
+			mapping: &api.ChartMapping{Charts: []string{"kafka"}, Name: "events"},
+		},
+		{
+			desc:    "rename several charts",
+			mapping: &api.ChartMapping{Charts: []string{"kafka*"}, Name: "events"},
+			wantErr: `"chartMappings[0].name" requires "charts" to contain a single chart name`,
+		},
+		{
+			desc:    "invalid pattern",
+			mapping: &api.ChartMapping{Charts: []string{"kafka["}},
+			wantErr: `"chartMappings[0].charts" contains an invalid pattern "kafka[": syntax error in pattern`,
+		},
+		{
+			desc:    "path escaping the target",
+			mapping: &api.ChartMapping{Path: "../db"},
+			wantErr: `"chartMappings[0].path" should be a relative path like "db" or "apps/web"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := &api.Config{
+				Source: &api.Source{
+					Repo: &api.Repo{Url: "https://charts.bitnami.com/bitnami", Kind: api.Kind_HELM},
+				},
+				Target: &api.Target{
+					Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+				},
+				ChartMappings: []*api.ChartMapping{tc.mapping},
+			}
+			err := config.Validate()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Errorf("got error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageMappingMap(t *testing.T) {
+	tests := []struct {
+		mapping    *api.ImageMapping
+		repository string
+		want       string
+		wantOk     bool
+	}{
+		{
+			mapping:    &api.ImageMapping{From: "docker.io/bitnami/*", To: "registry.internal/mirror/bitnami/*"},
+			repository: "docker.io/bitnami/kafka",
+			want:       "registry.internal/mirror/bitnami/kafka",
+			wantOk:     true,
+		},
+		{
+			mapping:    &api.ImageMapping{From: "docker.io/bitnami/*", To: "registry.internal/mirror/bitnami/*"},
+			repository: "docker.io/library/nginx",
+		},
+		{
+			mapping:    &api.ImageMapping{From: "quay.io/*", To: "registry.internal/quay"},
+			repository: "quay.io/prometheus/node-exporter",
+			want:       "registry.internal/quay",
+			wantOk:     true,
+		},
+		{
+			mapping:    &api.ImageMapping{From: "docker.io/bitnami/kafka", To: "registry.internal/kafka"},
+			repository: "docker.io/bitnami/kafka-exporter",
+		},
+	}
+	for _, tc := range tests {
+		got, ok := tc.mapping.Map(tc.repository)
+		if ok != tc.wantOk || got != tc.want {
+			t.Errorf("mapping %q with %v: got (%q, %v), want (%q, %v)", tc.repository, tc.mapping, got, ok, tc.want, tc.wantOk)
+		}
+	}
+}
+
+func TestValidateImageMappings(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Path: "/tmp/charts", Kind: api.Kind_LOCAL},
+			Containers: &api.Containers{
+				ImageMappings: []*api.ImageMapping{{From: "docker.io/bitnami/*", To: "registry.internal/bitnami/*"}},
+			},
+		},
+	}
+
+	expectedError := `"target.containers.imageMappings" is only supported by OCI targets`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+
+	config.Target.Repo = &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateSigning(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo:    &api.Repo{Path: "/tmp/charts", Kind: api.Kind_LOCAL},
+			Signing: &api.Signing{SignChartsIndex: true},
+		},
+	}
+
+	expectedError := `"target.signing" is only supported by OCI targets` + "\n" +
+		`"target.signing.privateKey" is required` + "\n" +
+		`"target.signing.signChartsIndex" requires "publishChartsIndex"`
+	if err := config.Validate(); err == nil || err.Error() != expectedError {
+		t.Errorf("got error %v, want %q", err, expectedError)
+	}
+
+	config.Target.Repo = &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI}
+	config.Target.PublishChartsIndex = true
+	config.Target.Signing.PrivateKey = "cosign.key"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bitnami/charts-syncer/internal/config"
+	"github.com/juju/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	configValidateExample = `
+  # Reports all the problems of the config file
+  charts-syncer config validate --config charts-syncer.yaml
+
+  # Also checks the source and target repositories are reachable
+  charts-syncer config validate --check-reachability`
+
+	configSchemaExample = `
+  # Writes the JSON Schema of the config file
+  charts-syncer config schema > charts-syncer.schema.json`
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Validates config files and shows their schema",
+	}
+	cmd.AddCommand(
+		newConfigValidateCmd(),
+		newConfigSchemaCmd(),
+	)
+	return cmd
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	var checkReachability bool
+
+	cmd := &cobra.Command{
+		Use:     "validate",
+		Short:   "Reports all the problems found in a config file",
+		Example: configValidateExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := initConfigFile(); err != nil {
+				return errors.Trace(err)
+			}
+			file := viper.ConfigFileUsed()
+
+			problems, err := config.Validate(file,
+				config.WithReachabilityChecks(checkReachability), config.WithInsecure(rootInsecure))
+			if err != nil {
+				return errors.Trace(err)
+			}
+
+			var errCount int
+			for _, p := range problems {
+				if !p.Warning {
+					errCount++
+				}
+				cmd.Printf("%s: %s\n", file, p)
+			}
+			if errCount > 0 {
+				return errors.Errorf("%q config file has %d errors", file, errCount)
+			}
+			cmd.Printf("%s: the config file is valid\n", file)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&checkReachability, "check-reachability", false, "Check the source and target repositories are reachable")
+
+	return cmd
+}
+
+func newConfigSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "schema",
+		Short:   "Prints the JSON Schema of the config file",
+		Example: configSchemaExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			schema, err := config.Schema()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(schema))
+			return nil
+		},
+	}
+}
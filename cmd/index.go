@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/config"
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	"github.com/juju/errors"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/klog"
+)
+
+// indexRebuildTimeout is the maximum time to crawl a registry and push its
+// charts index
+const indexRebuildTimeout = 30 * time.Minute
+
+var (
+	indexRebuildExample = `
+  # Rebuilds the charts index of the targets publishing one in the config file
+  charts-syncer index rebuild
+
+  # Shows the charts index of a registry without pushing it
+  charts-syncer index rebuild --repo-url https://my-registry.io/charts --dry-run`
+)
+
+func newIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Manages the charts index of OCI repositories",
+	}
+	cmd.AddCommand(newIndexRebuildCmd())
+	return cmd
+}
+
+func newIndexRebuildCmd() *cobra.Command {
+	var repoURL, indexRef string
+
+	cmd := &cobra.Command{
+		Use:   "rebuild",
+		Short: "Creates the charts index of an OCI repository from scratch",
+		Long: `Creates the charts index of an OCI repository from scratch, crawling the registry catalog to find all the charts published in the repository. The charts index of the targets publishing one in the config file is rebuilt, unless a repository is provided with --repo-url.
+
+The registry credentials can be provided with the TARGET_REPO_AUTH_USERNAME and TARGET_REPO_AUTH_PASSWORD env variables.`,
+		Example: indexRebuildExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var c api.Config
+			if repoURL != "" {
+				c.Target = &api.Target{
+					Repo:               &api.Repo{Kind: api.Kind_OCI, Url: repoURL, ChartsIndex: indexRef},
+					PublishChartsIndex: true,
+				}
+				if err := c.Validate(); err != nil {
+					return errors.Trace(err)
+				}
+				if err := config.InitEnvBindings(); err != nil {
+					return errors.Trace(err)
+				}
+				if err := config.SetDefaults(&c); err != nil {
+					return errors.Trace(err)
+				}
+			} else {
+				if err := initConfigFile(); err != nil {
+					return errors.Trace(err)
+				}
+				if err := config.InitEnvBindings(); err != nil {
+					return errors.Trace(err)
+				}
+				if err := config.Load(&c); err != nil {
+					return errors.Trace(err)
+				}
+				if err := c.Validate(); err != nil {
+					return errors.Trace(err)
+				}
+			}
+
+			var rebuilt int
+			for _, t := range c.AllTargets() {
+				if !t.GetPublishChartsIndex() {
+					continue
+				}
+				if err := rebuildIndex(cmd, t.GetRepo()); err != nil {
+					return errors.Trace(err)
+				}
+				rebuilt++
+			}
+			if rebuilt == 0 {
+				return errors.Errorf(`no target publishes a charts index. Enable "publishChartsIndex" or use --repo-url`)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&repoURL, "repo-url", "", "URL of the OCI repository to index instead of the config file targets")
+	cmd.Flags().StringVar(&indexRef, "index-ref", "", "OCI reference of the --repo-url charts index. Defaults to <repo-url>/charts-index:latest")
+
+	return cmd
+}
+
+// rebuildIndex crawls repo and pushes its charts index. In dry-run mode, the
+// index is printed instead.
+func rebuildIndex(cmd *cobra.Command, repo *api.Repo) error {
+	opts := []indexer.OciIndexerOpt{
+		indexer.WithHost(repo.GetUrl()),
+		indexer.WithBasicAuth(repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword()),
+		indexer.WithIndexRef(repo.GetChartsIndex()),
+	}
+	if rootInsecure {
+		opts = append(opts, indexer.WithInsecure())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), indexRebuildTimeout)
+	defer cancel()
+
+	klog.Infof("Crawling %q...", repo.GetUrl())
+	idx, err := indexer.Crawl(ctx, opts...)
+	if err != nil {
+		return errors.Annotatef(err, "crawling %q", repo.GetUrl())
+	}
+	var versions int
+	for _, e := range idx.GetEntries() {
+		versions += len(e.GetVersions())
+	}
+
+	if rootDryRun {
+		data, err := protojson.MarshalOptions{Multiline: true}.Marshal(idx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	pub, err := indexer.NewOciPublisher(opts...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := pub.Push(ctx, idx); err != nil {
+		return errors.Trace(err)
+	}
+	cmd.Printf("%q charts index rebuilt with %d charts and %d versions\n", repo.GetChartsIndex(), len(idx.GetEntries()), versions)
+	return nil
+}
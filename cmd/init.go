@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/config"
+	"github.com/juju/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initExample = `
+  # Asks for the source and target repositories and writes ./charts-syncer.yaml
+  charts-syncer init
+
+  # Scaffolds the config file without prompting
+  charts-syncer init --non-interactive \
+    --source-url https://charts.bitnami.com/bitnami \
+    --target-url https://my-registry.io/charts \
+    --charts redis,mariadb`
+)
+
+type initOptions struct {
+	sourceKind         string
+	sourceURL          string
+	sourcePath         string
+	targetKind         string
+	targetURL          string
+	targetPath         string
+	containersURL      string
+	charts             []string
+	skipCharts         []string
+	containerPlatforms []string
+	output             string
+	force              bool
+	noProbe            bool
+	nonInteractive     bool
+}
+
+func newInitCmd() *cobra.Command {
+	o := &initOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "init",
+		Short:   "Scaffolds a commented config file",
+		Long:    "Scaffolds a commented config file, asking for the values not provided as flags. The source repository is probed to find out how its charts can be discovered.",
+		Example: initExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !o.force {
+				if _, err := os.Stat(o.output); err == nil {
+					return errors.Errorf("%q already exists. Use --force to overwrite it", o.output)
+				}
+			}
+
+			p := &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout(), enabled: !o.nonInteractive}
+			s, err := o.scaffold(p)
+			if err != nil {
+				return errors.Trace(err)
+			}
+
+			var buf bytes.Buffer
+			if err := config.WriteScaffold(&buf, s); err != nil {
+				return errors.Trace(err)
+			}
+			if err := writeValidConfig(o.output, buf.Bytes()); err != nil {
+				return errors.Trace(err)
+			}
+			cmd.Printf("Config file written to %q. Run \"charts-syncer sync --config %s --dry-run\" to check the charts to sync\n", o.output, o.output)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.sourceKind, "source-kind", "", "Kind of the source repository: HELM, CHARTMUSEUM, HARBOR, OCI or LOCAL. Guessed probing the source if empty")
+	f.StringVar(&o.sourceURL, "source-url", "", "URL of the source repository")
+	f.StringVar(&o.sourcePath, "source-path", "", "Directory of the source repository if its kind is LOCAL")
+	f.StringVar(&o.targetKind, "target-kind", "OCI", "Kind of the target repository: OCI or LOCAL")
+	f.StringVar(&o.targetURL, "target-url", "", "URL of the target repository")
+	f.StringVar(&o.targetPath, "target-path", "", "Directory of the target repository if its kind is LOCAL")
+	f.StringVar(&o.containersURL, "containers-url", "", "Registry where the container images are pushed. Defaults to the target repository")
+	f.StringSliceVar(&o.charts, "charts", nil, "Charts to sync. All by default")
+	f.StringSliceVar(&o.skipCharts, "skip-charts", nil, "Charts not to sync")
+	f.StringSliceVar(&o.containerPlatforms, "container-platforms", nil, "Container platforms to sync. All by default")
+	f.StringVarP(&o.output, "output", "o", defaultCfgFile, "Path of the config file to write")
+	f.BoolVar(&o.force, "force", false, "Overwrite the config file if it exists")
+	f.BoolVar(&o.noProbe, "no-probe", false, "Do not probe the source repository")
+	f.BoolVar(&o.nonInteractive, "non-interactive", false, "Do not ask for the values not provided as flags")
+
+	return cmd
+}
+
+// scaffold composes the config file from the flags, asking for the missing
+// values
+func (o *initOptions) scaffold(p *prompter) (*config.Scaffold, error) {
+	var err error
+	source := &api.Repo{}
+
+	sourceKind := o.sourceKind
+	if sourceKind == "" && !o.noProbe {
+		// The kind can be guessed probing the source
+		sourceKind, err = p.ask("Source repository kind (HELM, CHARTMUSEUM, HARBOR, OCI, LOCAL). Leave empty to guess it", "source-kind", "", false)
+	} else {
+		sourceKind, err = p.ask("Source repository kind (HELM, CHARTMUSEUM, HARBOR, OCI, LOCAL)", "source-kind", sourceKind, true)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if source.Kind, err = parseKind(sourceKind); err != nil {
+		return nil, errors.Annotatef(err, "invalid source kind")
+	}
+	if source.Kind == api.Kind_LOCAL {
+		source.Path, err = p.ask("Source repository directory", "source-path", o.sourcePath, true)
+	} else {
+		source.Url, err = p.ask("Source repository URL", "source-url", o.sourceURL, true)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var probe *config.ProbeResult
+	if !o.noProbe {
+		fmt.Fprintln(p.out, "Probing the source repository...")
+		probe, err = config.ProbeSource(source, rootInsecure)
+		if err != nil {
+			return nil, errors.Annotatef(err, "probing the source repository. Use --no-probe to skip it")
+		}
+		source.Kind = probe.Kind
+		fmt.Fprintf(p.out, "Found a %s repository with %d charts\n", probe.Kind, probe.Charts)
+	} else if source.Kind == api.Kind_UNKNOWN {
+		return nil, errors.Errorf("the source kind is required when the source is not probed")
+	}
+
+	target := &api.Repo{}
+	targetKind, err := p.ask("Target repository kind (OCI, LOCAL)", "target-kind", o.targetKind, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if target.Kind, err = parseKind(targetKind); err != nil {
+		return nil, errors.Annotatef(err, "invalid target kind")
+	}
+	if target.Kind == api.Kind_LOCAL {
+		target.Path, err = p.ask("Target directory", "target-path", o.targetPath, true)
+	} else {
+		target.Url, err = p.ask("Target repository URL", "target-url", o.targetURL, true)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	s := &config.Scaffold{
+		Source:             source,
+		Target:             target,
+		ContainersURL:      o.containersURL,
+		Charts:             o.charts,
+		SkipCharts:         o.skipCharts,
+		ContainerPlatforms: o.containerPlatforms,
+		Probe:              probe,
+	}
+	// Charts must be listed if there is no way to discover them
+	requireCharts := probe != nil && probe.Kind == api.Kind_OCI && probe.ChartsIndex == ""
+	if len(s.Charts) == 0 && len(s.SkipCharts) == 0 {
+		question := "Charts to sync, separated by commas. Leave empty to sync all"
+		if requireCharts {
+			question = "Charts to sync, separated by commas"
+		}
+		charts, err := p.ask(question, "charts", "", requireCharts)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		s.Charts = splitList(charts)
+	}
+	return s, nil
+}
+
+// writeValidConfig writes data into path only if it is a valid config file
+func writeValidConfig(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".charts-syncer-*.yaml")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Trace(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Trace(err)
+	}
+
+	problems, err := config.Validate(tmp.Name())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var errs []string
+	for _, p := range problems {
+		if !p.Warning {
+			errs = append(errs, p.Message)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("the resulting config file is not valid:\n%s", strings.Join(errs, "\n"))
+	}
+	return errors.Trace(os.Rename(tmp.Name(), path))
+}
+
+func parseKind(s string) (api.Kind, error) {
+	if s == "" {
+		return api.Kind_UNKNOWN, nil
+	}
+	k, ok := api.Kind_value[strings.ToUpper(s)]
+	if !ok {
+		return api.Kind_UNKNOWN, errors.Errorf("unknown kind %q", s)
+	}
+	return api.Kind(k), nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// prompter asks for the values not provided as flags
+type prompter struct {
+	in      *bufio.Reader
+	out     io.Writer
+	enabled bool
+}
+
+// ask returns value if it is not empty. Otherwise, it asks for it if prompts
+// are enabled. The flag argument is the flag used to provide the value.
+func (p *prompter) ask(question, flag, value string, required bool) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if !p.enabled {
+		if required {
+			return "", errors.Errorf("--%s flag is required", flag)
+		}
+		return "", nil
+	}
+	for {
+		fmt.Fprintf(p.out, "%s: ", question)
+		answer, err := p.in.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer != "" || !required {
+			return answer, nil
+		}
+		if err != nil {
+			return "", errors.Annotatef(err, "reading %q", question)
+		}
+	}
+}
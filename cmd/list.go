@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/config"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	cs "github.com/bitnami/charts-syncer/pkg/client/source"
+	ct "github.com/bitnami/charts-syncer/pkg/client/target"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/bitnami/charts-syncer/pkg/syncer"
+	"github.com/juju/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	listExample = `
+  # Lists the charts in the source repositories, applying the config file filters
+  charts-syncer list
+
+  # Lists the latest version of the charts in the target repositories as JSON
+  charts-syncer list --target --latest-version-only -o json
+
+  # Lists the redis charts of a repository without a config file
+  charts-syncer list --repo-url https://charts.bitnami.com/bitnami --kind HELM --charts redis`
+)
+
+type listOptions struct {
+	target            bool
+	repoURL           string
+	kind              string
+	charts            []string
+	skipCharts        []string
+	fromDate          string
+	latestVersionOnly bool
+	output            string
+	workdir           string
+	usePlainHTTP      bool
+}
+
+// listEntry is a chart version found in a repository
+type listEntry struct {
+	Repository string `json:"repository"`
+	*syncer.ChartVersion
+}
+
+// namedReader is a charts reader and the repository it reads from
+type namedReader struct {
+	name   string
+	reader client.ChartsReader
+}
+
+func newListCmd() *cobra.Command {
+	o := &listOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the charts found in the source or target repositories",
+		Long:    "Lists the charts found in the source or target repositories, applying the same filters as a sync. A repository can also be provided with --repo-url and --kind, ignoring the config file.",
+		Example: listExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			switch o.output {
+			case "table", "json", "yaml":
+			default:
+				return errors.Errorf("unsupported output format %q. Use \"table\", \"json\" or \"yaml\"", o.output)
+			}
+
+			readers, names, err := o.readers(cmd)
+			if err != nil {
+				return errors.Trace(err)
+			}
+
+			listOpts := []syncer.Option{
+				syncer.WithSkipCharts(o.skipCharts),
+				syncer.WithFromDate(o.fromDate),
+				syncer.WithLatestVersionOnly(o.latestVersionOnly),
+			}
+			entries := []listEntry{}
+			var errs error
+			for _, r := range readers {
+				versions, err := syncer.List(r.reader, names, listOpts...)
+				if err != nil {
+					klog.Warningf("There were some problems listing the charts of %q: %v", r.name, err)
+					errs = goerrors.Join(errs, errors.Annotatef(err, "listing %q", r.name))
+				}
+				for _, v := range versions {
+					entries = append(entries, listEntry{Repository: r.name, ChartVersion: v})
+				}
+			}
+
+			if err := printList(cmd.OutOrStdout(), o.output, entries); err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(errs)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&o.target, "target", false, "List the target repositories instead of the source ones")
+	f.StringVar(&o.repoURL, "repo-url", "", "URL of a repository to list instead of the ones in the config file. The directory of LOCAL repositories")
+	f.StringVar(&o.kind, "kind", "HELM", "Kind of the --repo-url repository: HELM, CHARTMUSEUM, HARBOR, OCI or LOCAL")
+	f.StringSliceVar(&o.charts, "charts", nil, "Charts to list. Defaults to the config file ones, or all")
+	f.StringSliceVar(&o.skipCharts, "skip-charts", nil, "Charts not to list. Defaults to the config file ones")
+	f.StringVar(&o.fromDate, "from-date", "", "List only the charts published from this date. Format: YYYY-MM-DD, RFC3339 or a duration relative to now, like 72h or 14d")
+	f.BoolVar(&o.latestVersionOnly, "latest-version-only", false, "List only the latest version of each chart")
+	f.StringVarP(&o.output, "output", "o", "table", "Output format: table, json or yaml")
+	f.StringVar(&o.workdir, "workdir", syncer.DefaultWorkdir(), "Working directory")
+	f.BoolVar(&o.usePlainHTTP, "use-plain-http", false, "Use plain HTTP instead of HTTPS")
+
+	return cmd
+}
+
+// readers returns the clients of the repositories to list and the charts to
+// list from them
+func (o *listOptions) readers(cmd *cobra.Command) ([]namedReader, []string, error) {
+	var c api.Config
+	if o.repoURL != "" {
+		k, err := parseKind(o.kind)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		repo := &api.Repo{Kind: k, Url: o.repoURL}
+		if repo.Kind == api.Kind_LOCAL {
+			repo.Url, repo.Path = "", o.repoURL
+		}
+		c.Source = &api.Source{Repo: repo}
+		if err := c.Validate(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := config.InitEnvBindings(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := config.SetDefaults(&c); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	} else {
+		if err := initConfigFile(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := config.InitEnvBindings(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := config.Load(&c); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := c.Validate(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
+	configureRateLimit(&c)
+
+	names := c.GetCharts()
+	if cmd.Flags().Changed("charts") {
+		names = o.charts
+	}
+	if !cmd.Flags().Changed("skip-charts") {
+		o.skipCharts = c.GetSkipCharts()
+	}
+
+	clientOpts := []types.Option{types.WithCache(o.workdir), types.WithInsecure(rootInsecure), types.WithUsePlainHTTP(o.usePlainHTTP)}
+	var readers []namedReader
+	if o.target && o.repoURL == "" {
+		for _, t := range c.AllTargets() {
+			r, err := ct.NewClient(t, clientOpts...)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			readers = append(readers, namedReader{name: repoID(t.GetRepo()), reader: r})
+		}
+		return readers, names, nil
+	}
+	for _, s := range c.AllSources() {
+		r, err := cs.NewClient(s, clientOpts...)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		readers = append(readers, namedReader{name: repoID(s.GetRepo()), reader: r})
+	}
+	return readers, names, nil
+}
+
+// repoID returns a human readable identifier for a repository
+func repoID(repo *api.Repo) string {
+	if repo.GetKind() == api.Kind_LOCAL {
+		return repo.GetPath()
+	}
+	return repo.GetUrl()
+}
+
+func printList(w io.Writer, format string, entries []listEntry) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return errors.Trace(err)
+	case "yaml":
+		data, err := yaml.Marshal(entries)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = w.Write(data)
+		return errors.Trace(err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tNAME\tVERSION\tPUBLISHED\tDIGEST")
+	for _, e := range entries {
+		published := "-"
+		if !e.PublishedAt.IsZero() {
+			published = e.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		digest := e.Digest
+		if digest == "" {
+			digest = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Repository, e.Name, e.Version, published, digest)
+	}
+	return errors.Trace(tw.Flush())
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListKind(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("../testdata/apache-7.3.15.wrap.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "apache-7.3.15.wrap.tgz"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The kind is case insensitive, as in init
+	chartsyncer("list", "--repo-url", dir, "--kind", "local").AssertSuccessMatchStdout(t, `apache\s+7.3.15`)
+	chartsyncer("list", "--repo-url", dir, "--kind", "LOCAL").AssertSuccessMatchStdout(t, `apache\s+7.3.15`)
+	chartsyncer("list", "--repo-url", dir, "--kind", "foo").AssertErrorMatch(t, `unknown kind "foo"`)
+}
@@ -49,6 +49,11 @@ func newRootCmd() *cobra.Command {
 	// Add subcommands
 	cmd.AddCommand(
 		newSyncCmd(),
+		newInitCmd(),
+		newListCmd(),
+		newVerifyCmd(),
+		newIndexCmd(),
+		newConfigCmd(),
 		newVersionCmd(),
 	)
 
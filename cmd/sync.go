@@ -1,9 +1,13 @@
 package main
 
 import (
+	goerrors "errors"
+	"time"
+
 	"github.com/bitnami/charts-syncer/api"
 	"github.com/bitnami/charts-syncer/internal/config"
 	klogLogger "github.com/bitnami/charts-syncer/internal/log"
+	"github.com/bitnami/charts-syncer/internal/throttle"
 	"github.com/bitnami/charts-syncer/pkg/syncer"
 	"github.com/juju/errors"
 	"github.com/mitchellh/go-homedir"
@@ -18,6 +22,7 @@ var (
 	syncFromDate          string
 	syncWorkdir           string
 	syncLatestVersionOnly bool
+	syncMaxBandwidth      string
 	usePlainHTTP          bool
 )
 
@@ -27,7 +32,13 @@ var (
   charts-syncer sync
 
   # Synchronizes all charts defined in the configuration file from May 1st, 2020
-  charts-syncer sync --from-date 2020-05-01`
+  charts-syncer sync --from-date 2020-05-01
+
+  # Synchronizes all charts published in the last two weeks
+  charts-syncer sync --from-date 14d
+
+  # Synchronizes all charts using at most 10MB per second
+  charts-syncer sync --max-bandwidth 10MB`
 )
 
 func initConfigFile() error {
@@ -82,9 +93,14 @@ func newSyncCmd() *cobra.Command {
 				return errors.Trace(err)
 			}
 
-			if err := c.Validate(); err != nil {
+			if err := goerrors.Join(c.Validate(), config.ValidateAllowedWindows(&c)); err != nil {
+				return errors.Trace(err)
+			}
+
+			if err := configureBandwidth(&c, syncMaxBandwidth); err != nil {
 				return errors.Trace(err)
 			}
+			configureRateLimit(&c)
 
 			return nil
 		},
@@ -104,10 +120,20 @@ func newSyncCmd() *cobra.Command {
 				syncer.WithFromDate(syncFromDate),
 				syncer.WithWorkdir(syncWorkdir),
 				syncer.WithContainerPlatforms(c.GetContainerPlatforms()),
+				syncer.WithImageDiscovery(c.GetImageDiscovery()...),
 				syncer.WithInsecure(rootInsecure),
 				syncer.WithLatestVersionOnly(syncLatestVersionOnly),
 				syncer.WithSkipArtifacts(c.GetSkipArtifacts()),
 				syncer.WithSkipCharts(c.SkipCharts),
+				syncer.WithTargets(c.GetTargets()...),
+				syncer.WithSources(c.GetSources()...),
+				syncer.WithConflictPolicy(c.GetConflictPolicy()),
+				syncer.WithChartMappings(c.GetChartMappings()...),
+				syncer.WithVerification(c.GetVerification()),
+				syncer.WithScan(c.GetScan()),
+				syncer.WithCommandHooks(c.GetHooks()...),
+				syncer.WithTransform(c.GetTransform()),
+				syncer.WithAllowedWindows(c.GetAllowedWindows()...),
 				syncer.WithUsePlainHTTP(usePlainHTTP),
 				syncer.WithLogger(l),
 			}
@@ -115,11 +141,18 @@ func newSyncCmd() *cobra.Command {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			// The request quotas left are shown in the summary whatever the result
+			defer func() { printQuotas(parentLog, s.Report()) }()
 			if err := s.SyncPendingCharts(c.GetCharts()...); err != nil {
 				if err == syncer.ErrNoChartsToSync {
 					parentLog.Successf("There are no charts out of sync!")
 					return nil
 				}
+				// The charts are pushed by the next sync inside a window
+				if err == syncer.ErrOutsideWindows {
+					parentLog.Successf("Charts wrapped successfully, they will be pushed inside the next allowed window")
+					return nil
+				}
 				return l.Failf("Error syncing charts: %v", err)
 			}
 			parentLog.Successf("Charts synced successfully")
@@ -127,11 +160,65 @@ func newSyncCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&syncFromDate, "from-date", "", "Date you want to synchronize charts from. Format: YYYY-MM-DD")
+	cmd.Flags().StringVar(&syncFromDate, "from-date", "", "Date you want to synchronize charts from. Format: YYYY-MM-DD, RFC3339 or a duration relative to now, like 72h or 14d")
 	cmd.Flags().StringVar(&syncWorkdir, "workdir", syncer.DefaultWorkdir(), "Working directory")
 	cmd.Flags().BoolVar(&syncLatestVersionOnly, "latest-version-only", false, "Sync only latest version of each chart")
+	cmd.Flags().StringVar(&syncMaxBandwidth, "max-bandwidth", "", `Maximum bandwidth of the transfers, like "512KiB" or "10MB" per second. Overrides "bandwidth.max"`)
 	cmd.Flags().BoolVar(&usePlainHTTP, "use-plain-http", false, "Use plain HTTP instead of HTTPS")
 	cmd.Flags().BoolVar(&usePlainLog, "use-plain-log", false, "Use plain klog instead of the pretty logging")
 
 	return cmd
 }
+
+// printQuotas prints the last request quotas announced by the registries and
+// chart repositories
+func printQuotas(l log.Logger, r *syncer.Report) {
+	for _, q := range r.Quotas {
+		l.Infof("%s", q)
+	}
+}
+
+// configureBandwidth throttles the chart downloads and the container image
+// transfers with the bandwidth limits of the config file. A non-empty max
+// overrides the global limit.
+func configureBandwidth(c *api.Config, max string) error {
+	if max == "" {
+		max = c.GetBandwidth().GetMax()
+	}
+	var global int64
+	if max != "" {
+		var err error
+		if global, err = api.ParseBandwidth(max); err != nil {
+			return errors.Errorf("invalid maximum bandwidth: %v", err)
+		}
+	}
+	perRegistry := map[string]int64{}
+	for _, r := range c.GetBandwidth().GetRegistries() {
+		// The config file has already been validated
+		limit, _ := api.ParseBandwidth(r.GetMax())
+		perRegistry[r.GetRegistry()] = limit
+	}
+	if global > 0 || len(perRegistry) > 0 {
+		klog.V(3).Infof("Throttling the transfers to %d bytes per second, and %v per registry", global, perRegistry)
+	}
+	throttle.Configure(global, perRegistry)
+	return nil
+}
+
+// configureRateLimit paces the requests to the registries and chart
+// repositories with the request rate limits of the config file, and retries
+// the requests they reject with a 429 status
+func configureRateLimit(c *api.Config) {
+	r := c.GetRateLimit()
+	perRegistry := map[string]float64{}
+	for _, l := range r.GetRegistries() {
+		perRegistry[l.GetRegistry()] = l.GetRequestsPerSecond()
+	}
+	maxWait := throttle.DefaultMaxRetryWait
+	if r.GetMaxRetryWait() != "" {
+		// The config file has already been validated
+		maxWait, _ = time.ParseDuration(r.GetMaxRetryWait())
+	}
+	throttle.ConfigureRequests(r.GetRequestsPerSecond(), perRegistry, maxWait)
+	throttle.RetryImageTransfers(rootInsecure)
+}
@@ -3,17 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"slices"
 	"testing"
 	"text/template"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	indexapi "github.com/bitnami/charts-syncer/internal/indexer/api"
 	"github.com/bitnami/charts-syncer/internal/utils"
 	"github.com/bitnami/charts-syncer/pkg/client/repo/oci"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
 	"github.com/stretchr/testify/assert"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 var (
@@ -87,6 +91,100 @@ func TestSync(t *testing.T) {
 	}
 }
 
+func TestSyncImageMappings(t *testing.T) {
+	prepareSourceRepo(context.Background(), t)
+	oci.PrepareOCIServer(context.Background(), t, ociTargetRepo)
+	ct := oci.PrepareTest(t, ociTargetRepo)
+
+	cfg, err := renderConfigFile("../testdata/sync-image-mappings-test.tmpl.yaml", "apache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(cfg) })
+
+	chartsyncer("sync", "--use-plain-log", "--use-plain-http", "--config", cfg).AssertSuccessMatchStderr(t, "Charts synced successfully")
+
+	chartPath, err := ct.Fetch("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(chartPath)
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, ok := c.Values["image"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("image not found in values.yaml")
+	}
+	assert.Equal(t, "registry.internal", image["registry"])
+	assert.Equal(t, "mirror/bitnami/apache", image["repository"])
+}
+
+func TestSyncMaxBandwidth(t *testing.T) {
+	prepareSourceRepo(context.Background(), t)
+	oci.PrepareOCIServer(context.Background(), t, ociTargetRepo)
+	ct := oci.PrepareTest(t, ociTargetRepo)
+
+	cfg, err := renderConfigFile("../testdata/sync-test.tmpl.yaml", "apache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(cfg) })
+
+	args := []string{"sync", "--use-plain-log", "--use-plain-http", "--config", cfg}
+	chartsyncer(append(args, "--max-bandwidth", "10Mbps")...).AssertErrorMatch(t, `invalid maximum bandwidth: should be a bandwidth like "512KiB" or "10MB", got "10Mbps"`)
+	chartsyncer(append(args, "--max-bandwidth", "50MB")...).AssertSuccessMatchStderr(t, "Charts synced successfully")
+	assert.NoError(t, verifyChart(ct, "apache", "7.3.15"))
+}
+
+func TestSyncPublishChartsIndex(t *testing.T) {
+	prepareSourceRepo(context.Background(), t)
+	oci.PrepareOCIServer(context.Background(), t, ociTargetRepo)
+
+	getIndex := func() *indexapi.Index {
+		u, err := url.Parse(ociTargetRepo.Url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ind, err := indexer.NewOciIndexer(
+			indexer.WithHost(ociTargetRepo.Url),
+			indexer.WithIndexRef(u.Host+u.Path+"/charts-index:latest"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idx, err := ind.Get(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idx
+	}
+
+	// Each sync merges the synced charts into the index
+	for _, charts := range [][]string{{"apache"}, {"apache", "zookeeper"}} {
+		cfg, err := renderConfigFile("../testdata/sync-publish-index-test.tmpl.yaml", charts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Remove(cfg) })
+
+		chartsyncer("sync", "--use-plain-log", "--use-plain-http", "--config", cfg).AssertSuccessMatchStderr(t, "Charts synced successfully")
+	}
+	idx := getIndex()
+	assert.True(t, idx.Has("apache", "7.3.15"))
+	assert.True(t, idx.Has("zookeeper", "5.14.3"))
+	assert.Len(t, idx.GetEntries(), 2)
+	assert.NotEmpty(t, idx.GetEntries()["apache"].GetVersions()[0].GetDigest())
+
+	// The index can be rebuilt from scratch crawling the registry
+	chartsyncer("index", "rebuild", "--repo-url", ociTargetRepo.Url).AssertSuccessMatchStderr(t, "rebuilt with 2 charts and 2 versions")
+	rebuilt := getIndex()
+	assert.True(t, rebuilt.Has("apache", "7.3.15"))
+	assert.True(t, rebuilt.Has("zookeeper", "5.14.3"))
+	assert.Equal(t, idx.GetEntries()["apache"].GetVersions()[0].GetDigest(), rebuilt.GetEntries()["apache"].GetVersions()[0].GetDigest())
+}
+
 func prepareSourceRepo(_ context.Context, t *testing.T) {
 	oci.PrepareOCIServer(context.Background(), t, ociSourceRepo)
 	cs := oci.PrepareTest(t, ociSourceRepo)
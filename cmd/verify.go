@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/config"
+	"github.com/bitnami/charts-syncer/pkg/syncer"
+	"github.com/juju/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	verifyExample = `
+  # Verifies all the charts in the target repositories are complete
+  charts-syncer verify
+
+  # Verifies the kafka charts in the target repositories, as JSON
+  charts-syncer verify --charts kafka -o json`
+)
+
+type verifyOptions struct {
+	charts       []string
+	output       string
+	workdir      string
+	usePlainHTTP bool
+}
+
+func newVerifyCmd() *cobra.Command {
+	o := &verifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verifies the charts in the target repositories are complete",
+		Long: "Verifies the charts in the target repositories are complete: all their container images exist in the target for every platform, " +
+			"and their digests are recorded in the charts index of the targets publishing one. It fails if any chart is broken or partially synced.",
+		Example: verifyExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			switch o.output {
+			case "table", "json", "yaml":
+			default:
+				return errors.Errorf("unsupported output format %q. Use \"table\", \"json\" or \"yaml\"", o.output)
+			}
+
+			var c api.Config
+			if err := initConfigFile(); err != nil {
+				return errors.Trace(err)
+			}
+			if err := config.InitEnvBindings(); err != nil {
+				return errors.Trace(err)
+			}
+			if err := config.Load(&c); err != nil {
+				return errors.Trace(err)
+			}
+			if err := c.Validate(); err != nil {
+				return errors.Trace(err)
+			}
+			configureRateLimit(&c)
+			names := c.GetCharts()
+			if cmd.Flags().Changed("charts") {
+				names = o.charts
+			}
+
+			verified, errs := syncer.VerifyTargets(names,
+				syncer.WithTargets(c.AllTargets()...),
+				syncer.WithChartMappings(c.GetChartMappings()...),
+				syncer.WithSkipCharts(c.GetSkipCharts()),
+				syncer.WithContainerPlatforms(c.GetContainerPlatforms()),
+				syncer.WithInsecure(rootInsecure),
+				syncer.WithUsePlainHTTP(o.usePlainHTTP),
+				syncer.WithWorkdir(o.workdir),
+			)
+			if errs != nil {
+				klog.Warningf("There were some problems verifying the targets: %v", errs)
+			}
+			if verified == nil {
+				verified = []*syncer.VerifiedChart{}
+			}
+			if err := printVerified(cmd.OutOrStdout(), o.output, verified); err != nil {
+				return errors.Trace(err)
+			}
+
+			incomplete := 0
+			for _, v := range verified {
+				if v.Status != syncer.StatusComplete {
+					incomplete++
+				}
+			}
+			if incomplete > 0 {
+				errs = goerrors.Join(errs, errors.Errorf("%d of %d charts are not complete", incomplete, len(verified)))
+			}
+			return errors.Trace(errs)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&o.charts, "charts", nil, "Charts to verify. Defaults to the config file ones, or all")
+	f.StringVarP(&o.output, "output", "o", "table", "Output format: table, json or yaml")
+	f.StringVar(&o.workdir, "workdir", syncer.DefaultWorkdir(), "Working directory")
+	f.BoolVar(&o.usePlainHTTP, "use-plain-http", false, "Use plain HTTP instead of HTTPS")
+
+	return cmd
+}
+
+func printVerified(w io.Writer, format string, verified []*syncer.VerifiedChart) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(verified, "", "  ")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return errors.Trace(err)
+	case "yaml":
+		data, err := yaml.Marshal(verified)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = w.Write(data)
+		return errors.Trace(err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tNAME\tVERSION\tIMAGES\tDIGEST\tSTATUS\tPROBLEMS")
+	for _, v := range verified {
+		problems := strings.Join(v.Problems, "; ")
+		if problems == "" {
+			problems = "-"
+		}
+		digest := "unverified"
+		if v.DigestVerified {
+			digest = "verified"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", v.Target, v.Name, v.Version, v.Images, digest, v.Status, problems)
+	}
+	return errors.Trace(tw.Flush())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/pkg/client/repo/oci"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestVerify(t *testing.T) {
+	prepareSourceRepo(context.Background(), t)
+	oci.PrepareOCIServer(context.Background(), t, ociTargetRepo)
+
+	cfg, err := renderConfigFile("../testdata/sync-publish-index-test.tmpl.yaml", "apache", "zookeeper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(cfg) })
+
+	chartsyncer("sync", "--use-plain-log", "--use-plain-http", "--config", cfg).AssertSuccessMatchStderr(t, "Charts synced successfully")
+	chartsyncer("verify", "--use-plain-http", "--config", cfg).AssertSuccessMatchStdout(t, `apache\s+7.3.15\s+0\s+verified\s+complete`)
+
+	// Push a chart out of band, using an image missing in the target
+	u, err := url.Parse(ociTargetRepo.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := loader.Load("../testdata/apache-7.3.15.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Metadata.Version = "7.3.16"
+	c.Metadata.Annotations = map[string]string{"images": "- name: apache\n  image: " + u.Host + u.Path + "/bitnami/apache:2.4.43\n"}
+	chartPath, err := chartutil.Save(c, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := oci.PrepareTest(t, ociTargetRepo).Upload(chartPath, c.Metadata); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(chartPath) != "apache-7.3.16.tgz" {
+		t.Fatalf("unexpected chart package %q", chartPath)
+	}
+
+	res := chartsyncer("verify", "--use-plain-http", "--config", cfg, "--charts", "apache")
+	res.AssertErrorMatch(t, "1 of 2 charts are not complete")
+	assert.Regexp(t, `apache\s+7.3.15\s+0\s+verified\s+complete`, res.stdout)
+	assert.Regexp(t, `apache\s+7.3.16\s+1\s+unverified\s+broken\s+not found in the charts index; ".*/bitnami/apache:2.4.43" image is missing`, res.stdout)
+}
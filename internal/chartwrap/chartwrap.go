@@ -0,0 +1,143 @@
+// Package chartwrap implements helpers to modify wrapped charts
+package chartwrap
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	dtutils "github.com/vmware-labs/distribution-tooling-for-helm/pkg/utils"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+	"k8s.io/klog"
+)
+
+// Rewrite extracts the wrapped chart in file, runs fn over the extracted wrap
+// and compresses the result into dest. file and dest can be the same path.
+func Rewrite(file, dest string, fn func(w wrapping.Wrap) error) (e error) {
+	dir, err := os.MkdirTemp("", "chartwrap")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil && e == nil {
+			e = errors.Trace(err)
+		}
+	}()
+
+	// Wraps are compressed with a "<name>-<version>" root folder
+	if err := dtutils.Untar(file, dir, dtutils.TarConfig{StripComponents: 1}); err != nil {
+		return errors.Annotatef(err, "extracting %q wrap", file)
+	}
+	w, err := wrapping.Load(dir)
+	if err != nil {
+		return errors.Annotatef(err, "loading %q wrap", file)
+	}
+	if err := fn(w); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Reload the wrap as fn could have modified the chart metadata
+	w, err = wrapping.Load(dir)
+	if err != nil {
+		return errors.Annotatef(err, "loading %q wrap", file)
+	}
+	prefix := fmt.Sprintf("%s-%s", w.Chart().Name(), w.Chart().Version())
+	klog.V(4).Infof("Compressing %q wrap into %q", prefix, dest)
+	if err := dtutils.Tar(dir, dest, dtutils.TarConfig{Prefix: prefix}); err != nil {
+		return errors.Annotatef(err, "compressing %q wrap", prefix)
+	}
+	return nil
+}
+
+// ReadImagesLock reads the Images.lock file of the wrapped chart in file,
+// without extracting the wrap. A not found error is returned if the chart has
+// no Images.lock file.
+func ReadImagesLock(file string) (*imagelock.ImagesLock, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Annotatef(err, "reading %q wrap", file)
+	}
+	defer gz.Close()
+
+	// Wraps are compressed with a "<name>-<version>" root folder
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.NotFoundf("%q Images.lock", file)
+		}
+		if err != nil {
+			return nil, errors.Annotatef(err, "reading %q wrap", file)
+		}
+		parts := strings.Split(strings.TrimPrefix(h.Name, "./"), "/")
+		if len(parts) == 3 && parts[1] == "chart" && parts[2] == imagelock.DefaultImagesLockFileName {
+			lock, err := imagelock.FromYAML(tr)
+			return lock, errors.Annotatef(err, "reading %q Images.lock", file)
+		}
+	}
+}
+
+// SetName renames the wrapped chart, updating the Images.lock file
+// accordingly so the wrap can still be verified when unwrapped.
+func SetName(w wrapping.Wrap, name string) error {
+	oldName := w.Chart().Name()
+	if err := dtutils.YamlFileSet(filepath.Join(w.ChartDir(), "Chart.yaml"), map[string]string{
+		"$.name": name,
+	}); err != nil {
+		return errors.Annotatef(err, "renaming %q chart", oldName)
+	}
+
+	return updateImagesLock(w, func(lock *imagelock.ImagesLock) {
+		lock.Chart.Name = name
+		for _, img := range lock.Images {
+			if img.Chart == oldName {
+				img.Chart = name
+			}
+		}
+	})
+}
+
+// SetVersion changes the version of the wrapped chart, updating the
+// Images.lock file accordingly so the wrap can still be verified when
+// unwrapped.
+func SetVersion(w wrapping.Wrap, version string) error {
+	if err := dtutils.YamlFileSet(filepath.Join(w.ChartDir(), "Chart.yaml"), map[string]string{
+		"$.version": version,
+	}); err != nil {
+		return errors.Annotatef(err, "setting %q chart version", w.Chart().Name())
+	}
+	return updateImagesLock(w, func(lock *imagelock.ImagesLock) {
+		lock.Chart.Version = version
+	})
+}
+
+// updateImagesLock runs fn over the Images.lock file of the wrapped chart, if
+// any, and writes the result
+func updateImagesLock(w wrapping.Wrap, fn func(lock *imagelock.ImagesLock)) error {
+	lockFile := w.LockFilePath()
+	if !dtutils.FileExists(lockFile) {
+		return nil
+	}
+	lock, err := imagelock.FromYAMLFile(lockFile)
+	if err != nil {
+		return errors.Annotatef(err, "loading %q", lockFile)
+	}
+	fn(lock)
+	f, err := os.Create(lockFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	return errors.Annotatef(lock.ToYAML(f), "writing %q", lockFile)
+}
@@ -0,0 +1,138 @@
+package chartwrap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestSetName(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "renamed.wrap.tgz")
+	if err := Rewrite("../../testdata/kafka-10.3.3.wrap.tgz", dest, func(w wrapping.Wrap) error {
+		return SetName(w, "internal-kafka")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the result is a valid wrap with the new name
+	if err := Rewrite(dest, dest, func(w wrapping.Wrap) error {
+		if got, want := w.Chart().Name(), "internal-kafka"; got != want {
+			t.Errorf("got chart name %q, want %q", got, want)
+		}
+		lock, err := w.GetImagesLock()
+		if err != nil {
+			return err
+		}
+		if got, want := lock.Chart.Name, "internal-kafka"; got != want {
+			t.Errorf("got Images.lock chart name %q, want %q", got, want)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadImagesLock(t *testing.T) {
+	lock, err := ReadImagesLock("../../testdata/kafka-10.3.3.wrap.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lock.Chart.Name, "kafka"; got != want {
+		t.Errorf("got Images.lock chart name %q, want %q", got, want)
+	}
+	if got, want := lock.Chart.Version, "10.3.3"; got != want {
+		t.Errorf("got Images.lock chart version %q, want %q", got, want)
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "suffixed.wrap.tgz")
+	if err := Rewrite("../../testdata/kafka-10.3.3.wrap.tgz", dest, func(w wrapping.Wrap) error {
+		return SetVersion(w, "10.3.3+mirror")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rewrite(dest, dest, func(w wrapping.Wrap) error {
+		if got, want := w.Chart().Version(), "10.3.3+mirror"; got != want {
+			t.Errorf("got chart version %q, want %q", got, want)
+		}
+		lock, err := w.GetImagesLock()
+		if err != nil {
+			return err
+		}
+		if got, want := lock.Chart.Version, "10.3.3+mirror"; got != want {
+			t.Errorf("got Images.lock chart version %q, want %q", got, want)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "transformed.wrap.tgz")
+	if err := Rewrite("../../testdata/kafka-10.3.3.wrap.tgz", dest, func(w wrapping.Wrap) error {
+		if err := SetValues(w, []Value{
+			{Path: "$.global.imageRegistry", Value: "registry.example.com"},
+			{Path: "global.imagePullSecrets", Value: []string{"regcred"}},
+			{Path: "metrics.kafka.enabled", Value: true},
+			{Path: "zookeeper.replicaCount", Value: 3},
+		}); err != nil {
+			return err
+		}
+		return SetAnnotations(w, map[string]string{"example.com/mirrored": "true"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rewrite(dest, dest, func(w wrapping.Wrap) error {
+		c, err := loader.LoadDir(w.ChartDir())
+		if err != nil {
+			return err
+		}
+		values := c.Values
+		global, _ := values["global"].(map[string]interface{})
+		if got, want := global["imageRegistry"], "registry.example.com"; got != want {
+			t.Errorf("got global.imageRegistry %v, want %q", got, want)
+		}
+		if got, ok := global["imagePullSecrets"].([]interface{}); !ok || len(got) != 1 || got[0] != "regcred" {
+			t.Errorf("got global.imagePullSecrets %v, want [regcred]", global["imagePullSecrets"])
+		}
+		metrics, _ := values["metrics"].(map[string]interface{})
+		kafka, _ := metrics["kafka"].(map[string]interface{})
+		if got := kafka["enabled"]; got != true {
+			t.Errorf("got metrics.kafka.enabled %v, want true", got)
+		}
+		zookeeper, _ := values["zookeeper"].(map[string]interface{})
+		if got := zookeeper["replicaCount"]; got != float64(3) {
+			t.Errorf("got zookeeper.replicaCount %v, want 3", got)
+		}
+		if got, want := c.Metadata.Annotations["example.com/mirrored"], "true"; got != want {
+			t.Errorf("got annotation %q, want %q", got, want)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetValuesInvalidPath(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "transformed.wrap.tgz")
+	for _, path := range []string{"", "$", "tolerations[3]", "global.imageRegistry.host"} {
+		if err := Rewrite("../../testdata/kafka-10.3.3.wrap.tgz", dest, func(w wrapping.Wrap) error {
+			return SetValues(w, []Value{
+				{Path: "global.imageRegistry", Value: "registry.example.com"},
+				{Path: path, Value: "value"},
+			})
+		}); err == nil {
+			t.Errorf("expected an error setting %q", path)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package chartwrap
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+	"gopkg.in/yaml.v3"
+)
+
+// pathSegmentRe matches a segment of a values path, like "tolerations[0]"
+var pathSegmentRe = regexp.MustCompile(`^([^\[\]]+)((?:\[\d+\])*)$`)
+
+// Value is a value set in the chart values
+type Value struct {
+	// Path to the key, like "$.global.imageRegistry" or "metrics.enabled"
+	Path  string
+	Value interface{}
+}
+
+// SetValues sets values in the values.yaml file of the wrapped chart. Missing
+// keys are created, but list items have to exist.
+func SetValues(w wrapping.Wrap, values []Value) error {
+	return editYAML(filepath.Join(w.ChartDir(), "values.yaml"), func(root *yaml.Node) error {
+		for _, v := range values {
+			n, err := encodeNode(v.Value)
+			if err != nil {
+				return errors.Annotatef(err, "encoding %q value", v.Path)
+			}
+			if err := setPath(root, v.Path, n); err != nil {
+				return errors.Annotatef(err, "setting %q value", v.Path)
+			}
+		}
+		return nil
+	})
+}
+
+// SetAnnotations adds annotations to the Chart.yaml file of the wrapped chart
+func SetAnnotations(w wrapping.Wrap, annotations map[string]string) error {
+	return SetChartAnnotations(w.ChartDir(), annotations)
+}
+
+// SetChartAnnotations adds annotations to the Chart.yaml file of the chart in
+// dir
+func SetChartAnnotations(dir string, annotations map[string]string) error {
+	return editYAML(filepath.Join(dir, "Chart.yaml"), func(root *yaml.Node) error {
+		node, err := mappingValue(root, "annotations")
+		if err != nil {
+			return errors.Annotatef(err, "setting annotations")
+		}
+		keys := make([]string, 0, len(annotations))
+		for k := range annotations {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			// Helm requires the annotations to be strings
+			setMappingValue(node, k, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: annotations[k]})
+		}
+		return nil
+	})
+}
+
+// editYAML runs fn over the root mapping of a YAML file, and writes the result
+func editYAML(file string, fn func(root *yaml.Node) error) error {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return errors.Annotatef(err, "parsing %q", file)
+	}
+	// Empty files have no document
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.Errorf("%q is not a YAML object", file)
+	}
+	if err := fn(doc.Content[0]); err != nil {
+		return errors.Trace(err)
+	}
+
+	var buf bytes.Buffer
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+	if err := e.Encode(doc); err != nil {
+		return errors.Annotatef(err, "encoding %q", file)
+	}
+	if err := e.Close(); err != nil {
+		return errors.Annotatef(err, "encoding %q", file)
+	}
+	return errors.Trace(os.WriteFile(file, buf.Bytes(), 0644))
+}
+
+// encodeNode returns the YAML node of a value
+func encodeNode(v interface{}) (*yaml.Node, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return doc.Content[0], nil
+}
+
+// setPath sets the node at path, creating the missing keys
+func setPath(root *yaml.Node, path string, value *yaml.Node) error {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return errors.NotValidf("empty path")
+	}
+	parts := strings.Split(path, ".")
+	node := root
+	for i, part := range parts {
+		m := pathSegmentRe.FindStringSubmatch(part)
+		if m == nil {
+			return errors.NotValidf("%q path segment", part)
+		}
+		var indexes []int
+		for _, idx := range strings.Split(strings.Trim(m[2], "[]"), "][") {
+			if idx == "" {
+				continue
+			}
+			n, _ := strconv.Atoi(idx)
+			indexes = append(indexes, n)
+		}
+
+		last := i == len(parts)-1
+		if last && len(indexes) == 0 {
+			if node.Kind != yaml.MappingNode {
+				return errors.Errorf("%q is not an object", part)
+			}
+			setMappingValue(node, m[1], value)
+			return nil
+		}
+		next, err := mappingValue(node, m[1])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for j, idx := range indexes {
+			if next.Kind != yaml.SequenceNode || idx >= len(next.Content) {
+				return errors.NotFoundf("%s[%d]", m[1], idx)
+			}
+			if last && j == len(indexes)-1 {
+				*next.Content[idx] = *value
+				return nil
+			}
+			next = next.Content[idx]
+		}
+		node = next
+	}
+	return nil
+}
+
+// mappingValue returns the value of a key of a mapping node. Missing and null
+// keys are set to an empty mapping.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errors.Errorf("%q parent is not an object", key)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			continue
+		}
+		v := node.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			*v = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		return v, nil
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	return v, nil
+}
+
+// setMappingValue sets the value of a key of a mapping node
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			// Keep the comments of the replaced value
+			value.LineComment = node.Content[i+1].LineComment
+			*node.Content[i+1] = *value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -2,9 +2,13 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/bitnami/charts-syncer/api"
@@ -24,20 +28,28 @@ const DefaultIndexName = "charts-index"
 // DefaultIndexTag is the tag for the OCI artifact with the index
 const DefaultIndexTag = "latest"
 
-func setDefaultChartsIndex(config *api.Config) error {
-	u, err := url.Parse(config.GetSource().GetRepo().GetUrl())
+func setDefaultChartsIndex(repo *api.Repo) error {
+	ref, err := defaultChartsIndex(repo.GetUrl())
 	if err != nil {
 		return err
 	}
-
-	uri := strings.Trim(strings.Join([]string{u.Host, u.Path}, "/"), "/")
-	ref := fmt.Sprintf("%s/%s:%s", uri, DefaultIndexName, DefaultIndexTag)
-	klog.V(4).Infof("'source.repo.chartsIndex' property is empty. Using %q default value", ref)
-	config.GetSource().GetRepo().ChartsIndex = ref
+	klog.V(4).Infof("'repo.chartsIndex' property is empty. Using %q default value", ref)
+	repo.ChartsIndex = ref
 
 	return nil
 }
 
+// defaultChartsIndex returns the default reference of the charts index of
+// the OCI repository in repoURL
+func defaultChartsIndex(repoURL string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", err
+	}
+	uri := strings.Trim(strings.Join([]string{u.Host, u.Path}, "/"), "/")
+	return fmt.Sprintf("%s/%s:%s", uri, DefaultIndexName, DefaultIndexTag), nil
+}
+
 // Load unmarshall config file into Config struct.
 func Load(config *api.Config) error {
 	// Load the config file
@@ -45,35 +57,52 @@ func Load(config *api.Config) error {
 		return errors.Trace(fmt.Errorf("error unmarshalling config file: %w", err))
 	}
 
-	if err := setDefaultOverrides(config); err != nil {
+	if err := SetDefaults(config); err != nil {
 		return errors.Trace(err)
 	}
 
-	if len(config.GetCharts()) > 0 && len(config.GetSkipCharts()) > 0 {
-		return errors.New("\"charts\" and \"skipCharts\" properties can not be set at the same time")
-	}
-
 	return nil
 }
 
-func setDefaultOverrides(config *api.Config) error {
-	if repo := config.GetSource().GetRepo(); repo != nil {
-		if !repo.GetDisableChartsIndex() && repo.GetChartsIndex() == "" {
-			if err := setDefaultChartsIndex(config); err != nil {
-				return err
+// SetDefaults sets the default values of the properties not found in the
+// config file, and the authentication provided as env variables
+func SetDefaults(config *api.Config) error {
+	for _, source := range config.AllSources() {
+		if repo := source.GetRepo(); repo != nil {
+			if !repo.GetDisableChartsIndex() && repo.GetChartsIndex() == "" {
+				if err := setDefaultChartsIndex(repo); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	// Target OCI Chart repositories do not use the custom index
-	if repo := config.GetTarget().GetRepo(); repo != nil {
-		if repo.Kind == api.Kind_OCI {
-			repo.DisableChartsIndex = true
+	// Target OCI Chart repositories do not use the custom index, although they
+	// might publish it
+	for _, target := range config.AllTargets() {
+		if repo := target.GetRepo(); repo != nil {
+			if repo.Kind == api.Kind_OCI {
+				repo.DisableChartsIndex = true
+			}
+			if target.GetPublishChartsIndex() && repo.GetChartsIndex() == "" {
+				if err := setDefaultChartsIndex(repo); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
-	// Container registry authentication override
-	if err := setAuthentication(config.GetSource(), config.GetTarget()); err != nil {
+	// Container registry authentication override. Without the legacy "source"
+	// and "target" properties, the env credentials apply to the first entry of
+	// "sources" and "targets"
+	source, target := config.GetSource(), config.GetTarget()
+	if source == nil && len(config.GetSources()) > 0 {
+		source = config.GetSources()[0]
+	}
+	if target == nil && len(config.GetTargets()) > 0 {
+		target = config.GetTargets()[0]
+	}
+	if err := setAuthentication(source, target); err != nil {
 		return err
 	}
 
@@ -83,22 +112,36 @@ func setDefaultOverrides(config *api.Config) error {
 // Sets the authentication configuration for container images and Helm Chart repositories
 // It reads the configuration from the viper config repository which values might come from the config file, env vars or flags
 func setAuthentication(source *api.Source, target *api.Target) error {
+	// Values coming from the config file are not interpolated by viper
+	i := &interpolator{baseDir: filepath.Dir(viper.ConfigFileUsed())}
+	var errs error
+	get := func(key string) string {
+		v := viper.GetString(key)
+		if fromEnv(key) {
+			// Env variables are passed through verbatim
+			return v
+		}
+		v, err := i.value(key, v)
+		errs = goerrors.Join(errs, err)
+		return v
+	}
+
 	// Source Chart and container images authentication
 	if source != nil {
 		// Helm Chart authentication
 		// NOTE: Getting entries one by one is required since they match the env variables defined and being overridden i.e SOURCE_containers.auth_REGISTRY
-		username, password := viper.GetString("source.repo.auth.username"), viper.GetString("source.repo.auth.password")
+		username, password := get("source.repo.auth.username"), get("source.repo.auth.password")
 		if username != "" && password != "" && source.GetRepo() != nil {
 			source.GetRepo().Auth = &api.Auth{Username: username, Password: password}
 		}
 
 		// Container images OCI repository authentication
-		username, password, registry := viper.GetString("source.containers.auth.username"), viper.GetString("source.containers.auth.password"), viper.GetString("source.containers.auth.registry")
+		username, password, registry := get("source.containers.auth.username"), get("source.containers.auth.password"), get("source.containers.auth.registry")
 		// Validation will happen in a later stage config.Validate()
 		// For now we set the struct value if any of the properties is available
 		if username != "" || password != "" {
 			if registry == "" {
-				registry = viper.GetString("source.containers.url")
+				registry = get("source.containers.url")
 			}
 			if source.GetContainers() == nil {
 				source.Containers = &api.Containers{}
@@ -109,16 +152,16 @@ func setAuthentication(source *api.Source, target *api.Target) error {
 
 	// Target Chart and container images authentication
 	if target != nil {
-		username, password := viper.GetString("target.repo.auth.username"), viper.GetString("target.repo.auth.password")
+		username, password := get("target.repo.auth.username"), get("target.repo.auth.password")
 		if username != "" && password != "" && target.GetRepo() != nil {
 			target.GetRepo().Auth = &api.Auth{Username: username, Password: password}
 		}
 
 		// Target container images OCI repository
-		username, password, registry := viper.GetString("target.containers.auth.username"), viper.GetString("target.containers.auth.password"), viper.GetString("target.containers.auth.registry")
+		username, password, registry := get("target.containers.auth.username"), get("target.containers.auth.password"), get("target.containers.auth.registry")
 		if username != "" || password != "" {
 			if registry == "" {
-				registry = viper.GetString("target.containers.url")
+				registry = get("target.containers.url")
 			}
 			if target.GetContainers() == nil {
 				target.Containers = &api.Containers{}
@@ -127,7 +170,7 @@ func setAuthentication(source *api.Source, target *api.Target) error {
 		}
 	}
 
-	return nil
+	return errs
 }
 
 // yamlToProto unmarshals `path` into the provided proto message
@@ -136,35 +179,58 @@ func yamlToProto(path string, v proto.Message) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	return yamlBytesToProto(yamlBytes, filepath.Dir(path), v)
+}
+
+// yamlBytesToProto converts a YAML document into a proto message, resolving
+// relative file references from baseDir
+func yamlBytesToProto(yamlBytes []byte, baseDir string, v proto.Message) error {
 	jsonBytes, err := yaml.YAMLToJSONStrict(yamlBytes)
 	if err != nil {
 		return errors.Trace(err)
 	}
+
+	// Resolve ${ENV_VAR} and ${file:/path} references
+	var doc interface{}
+	d := json.NewDecoder(bytes.NewReader(jsonBytes))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return errors.Trace(err)
+	}
+	i := &interpolator{baseDir: baseDir}
+	if doc, err = i.tree("", doc); err != nil {
+		return err
+	}
+	if jsonBytes, err = json.Marshal(doc); err != nil {
+		return errors.Trace(err)
+	}
+
 	err = protojson.Unmarshal(jsonBytes, v)
 	return errors.Trace(err)
 }
 
+// envBindings are the viper keys bound to env variables
+var envBindings = []struct {
+	// viper key associated with the env variable
+	key string
+	// name for the env variable in addition to the default one
+	// i.e source.containers.auth.registry => SOURCE_CONTAINERS_AUTH_REGISTRY
+	envNameFallback string
+}{
+	// Container Authentication
+	{key: "source.containers.auth.registry"}, {key: "source.containers.auth.username"}, {key: "source.containers.auth.password"},
+	{key: "target.containers.auth.registry"}, {key: "target.containers.auth.username"}, {key: "target.containers.auth.password"},
+
+	// Helm Chart repository authentication. Maintaining previous name for compatibility reasons
+	{key: "source.repo.auth.username", envNameFallback: "SOURCE_AUTH_USERNAME"}, {key: "source.repo.auth.password", envNameFallback: "SOURCE_AUTH_PASSWORD"},
+	{key: "target.repo.auth.username", envNameFallback: "TARGET_AUTH_USERNAME"}, {key: "target.repo.auth.password", envNameFallback: "TARGET_AUTH_PASSWORD"},
+}
+
 // InitEnvBindings defines the env variables bindings associated with local viper keys
 func InitEnvBindings() error {
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	boundKeys := []struct {
-		// viper key associated with the env variable
-		key string
-		// name for the env variable in addition to the default one
-		// i.e source.containers.auth.registry => SOURCE_CONTAINERS_AUTH_REGISTRY
-		envNameFallback string
-	}{
-		// Container Authentication
-		{key: "source.containers.auth.registry"}, {key: "source.containers.auth.username"}, {key: "source.containers.auth.password"},
-		{key: "target.containers.auth.registry"}, {key: "target.containers.auth.username"}, {key: "target.containers.auth.password"},
-
-		// Helm Chart repository authentication. Maintaining previous name for compatibility reasons
-		{key: "source.repo.auth.username", envNameFallback: "SOURCE_AUTH_USERNAME"}, {key: "source.repo.auth.password", envNameFallback: "SOURCE_AUTH_PASSWORD"},
-		{key: "target.repo.auth.username", envNameFallback: "TARGET_AUTH_USERNAME"}, {key: "target.repo.auth.password", envNameFallback: "TARGET_AUTH_PASSWORD"},
-	}
-
-	for _, k := range boundKeys {
+	for _, k := range envBindings {
 		if err := viper.BindEnv(k.key); err != nil {
 			return errors.Trace(err)
 		}
@@ -181,3 +247,19 @@ func InitEnvBindings() error {
 
 	return nil
 }
+
+// fromEnv returns whether the value of a viper key is set by one of its bound
+// env variables. Empty env variables are ignored, as viper does.
+func fromEnv(key string) bool {
+	for _, k := range envBindings {
+		if k.key != key {
+			continue
+		}
+		for _, name := range []string{strings.ToUpper(strings.ReplaceAll(key, ".", "_")), k.envNameFallback} {
+			if name != "" && os.Getenv(name) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bitnami/charts-syncer/api"
@@ -96,6 +98,19 @@ func TestGetAuthFromEnvVar(t *testing.T) {
 			&api.Containers_ContainerAuth{Username: "user123", Password: "sPasswordEnv", Registry: "sRegistry"},
 			&api.Containers_ContainerAuth{Username: "user456", Password: "tPasswordEnv", Registry: "test.registry.io"},
 		},
+		"env-vars-not-interpolated": {
+			"example-config-user-file.yaml",
+			map[string]string{
+				"SOURCE_REPO_AUTH_PASSWORD":       "pa${ss",
+				"TARGET_REPO_AUTH_PASSWORD":       "${file:password}",
+				"SOURCE_CONTAINERS_AUTH_PASSWORD": "$${HOME}",
+				"TARGET_CONTAINERS_AUTH_PASSWORD": "${HOME}",
+			},
+			&api.Auth{Username: "sourceUserFile", Password: "pa${ss"},
+			&api.Auth{Username: "targetUserFile", Password: "${file:password}"},
+			&api.Containers_ContainerAuth{Username: "user123", Password: "$${HOME}", Registry: "sRegistry"},
+			&api.Containers_ContainerAuth{Username: "user456", Password: "${HOME}", Registry: "test.registry.io"},
+		},
 		"full-file-existing-empty-env-vars": {
 			"example-config.yaml",
 			map[string]string{
@@ -172,3 +187,162 @@ func TestGetAuthFromEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "password"), []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CS_TEST_TARGET_HOST", "registry.example.com")
+	t.Setenv("CS_TEST_TARGET_USER", "robot")
+
+	tests := []struct {
+		desc       string
+		config     string
+		wantRepo   *api.Repo
+		wantErrors []string
+	}{
+		{
+			desc: "env vars and files",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: https://charts.example.com
+target:
+  repo:
+    kind: OCI
+    url: https://${CS_TEST_TARGET_HOST}/charts-$${literal}
+    auth:
+      username: ${CS_TEST_TARGET_USER}
+      password: ${file:password}
+`,
+			wantRepo: &api.Repo{
+				Kind:               api.Kind_OCI,
+				Url:                "https://registry.example.com/charts-${literal}",
+				Auth:               &api.Auth{Username: "robot", Password: "s3cr3t"},
+				DisableChartsIndex: true,
+			},
+		},
+		{
+			desc: "missing references",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: https://${CS_TEST_MISSING_HOST}
+target:
+  repo:
+    kind: OCI
+    url: https://${CS_TEST_TARGET_HOST}
+    auth:
+      username: ${CS_TEST_TARGET_USER}
+      password: ${file:missing}
+`,
+			wantErrors: []string{
+				`unable to resolve "${CS_TEST_MISSING_HOST}" in "source.repo.url": environment variable "CS_TEST_MISSING_HOST" is not set`,
+				`unable to resolve "${file:missing}" in "target.repo.auth.password"`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfgFile := filepath.Join(dir, "charts-syncer.yaml")
+			if err := os.WriteFile(cfgFile, []byte(tc.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			viper.Reset()
+			viper.SetConfigFile(cfgFile)
+			if err := viper.ReadInConfig(); err != nil {
+				t.Fatalf("error reading config file: %+v", err)
+			}
+
+			var syncConfig api.Config
+			err := Load(&syncConfig)
+			if len(tc.wantErrors) > 0 {
+				if err == nil {
+					t.Fatal("expected error but got nothing")
+				}
+				for _, want := range tc.wantErrors {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("got error %q, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := syncConfig.GetTarget().GetRepo(); !proto.Equal(got, tc.wantRepo) {
+				t.Errorf("got: %+v, want %+v", got, tc.wantRepo)
+			}
+		})
+	}
+}
+
+func TestGetAuthFromEnvVarWithoutLegacyTarget(t *testing.T) {
+	config := `
+sources:
+  - repo:
+      kind: HELM
+      url: https://charts.example.com
+  - repo:
+      kind: HELM
+      url: https://other-charts.example.com
+targets:
+  - repo:
+      kind: OCI
+      url: https://registry.example.com/charts
+  - repo:
+      kind: OCI
+      url: https://mirror.example.com/charts
+      auth:
+        username: mirrorUser
+        password: mirrorPassword
+`
+	cfgFile := filepath.Join(t.TempDir(), "charts-syncer.yaml")
+	if err := os.WriteFile(cfgFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOURCE_REPO_AUTH_USERNAME", "sUsername")
+	t.Setenv("SOURCE_REPO_AUTH_PASSWORD", "sPassword")
+	t.Setenv("TARGET_AUTH_USERNAME", "tUsername")
+	t.Setenv("TARGET_AUTH_PASSWORD", "tPassword")
+	t.Setenv("TARGET_CONTAINERS_AUTH_USERNAME", "tUsername")
+	t.Setenv("TARGET_CONTAINERS_AUTH_PASSWORD", "tPassword")
+
+	viper.Reset()
+	viper.SetConfigFile(cfgFile)
+	if err := InitEnvBindings(); err != nil {
+		t.Fatal(err)
+	}
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("error reading config file: %+v", err)
+	}
+	var syncConfig api.Config
+	if err := Load(&syncConfig); err != nil {
+		t.Fatalf("error loading config file: %+v", err)
+	}
+
+	// The env credentials apply to the first source and target
+	sources, targets := syncConfig.GetSources(), syncConfig.GetTargets()
+	if got, want := sources[0].GetRepo().GetAuth(), (&api.Auth{Username: "sUsername", Password: "sPassword"}); !proto.Equal(got, want) {
+		t.Errorf("got: %+v, want %+v", got, want)
+	}
+	if got, want := targets[0].GetRepo().GetAuth(), (&api.Auth{Username: "tUsername", Password: "tPassword"}); !proto.Equal(got, want) {
+		t.Errorf("got: %+v, want %+v", got, want)
+	}
+	if got, want := targets[0].GetContainers().GetAuth(), (&api.Containers_ContainerAuth{Username: "tUsername", Password: "tPassword"}); !proto.Equal(got, want) {
+		t.Errorf("got: %+v, want %+v", got, want)
+	}
+	// The rest keep their own credentials
+	if got := sources[1].GetRepo().GetAuth(); got != nil {
+		t.Errorf("got: %+v, want no auth", got)
+	}
+	if got, want := targets[1].GetRepo().GetAuth(), (&api.Auth{Username: "mirrorUser", Password: "mirrorPassword"}); !proto.Equal(got, want) {
+		t.Errorf("got: %+v, want %+v", got, want)
+	}
+	if got := targets[1].GetContainers().GetAuth(); got != nil {
+		t.Errorf("got: %+v, want no auth", got)
+	}
+}
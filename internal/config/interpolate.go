@@ -0,0 +1,114 @@
+package config
+
+import (
+	goerrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// filePrefix is the prefix of the references to files, i.e ${file:/path}
+const filePrefix = "file:"
+
+// referenceRE matches ${ENV_VAR} and ${file:/path} references. References
+// can be escaped with an extra "$", i.e $${ENV_VAR}.
+var referenceRE = regexp.MustCompile(`\$(\$?)\{([^}]*)\}`)
+
+// interpolator resolves the references found in the config file values. The
+// resolved values are never logged, as they usually contain credentials.
+type interpolator struct {
+	// directory used to resolve relative file references
+	baseDir string
+}
+
+// value replaces the references in s. The field argument is the path of the
+// value in the config file, and it is used to compose meaningful error
+// messages.
+func (i *interpolator) value(field, s string) (string, error) {
+	var errs error
+	res := referenceRE.ReplaceAllStringFunc(s, func(m string) string {
+		groups := referenceRE.FindStringSubmatch(m)
+		if groups[1] != "" {
+			// Escaped reference
+			return m[1:]
+		}
+		ref := groups[2]
+		v, err := i.resolve(ref)
+		if err != nil {
+			errs = goerrors.Join(errs, &api.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf(`unable to resolve "${%s}" in %q: %v`, ref, field, err),
+			})
+			return m
+		}
+		klog.V(4).Infof("Resolved %q reference in %q", ref, field)
+		return v
+	})
+	return res, errs
+}
+
+func (i *interpolator) resolve(ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, filePrefix); ok {
+		if path == "" {
+			return "", errors.New("empty file path")
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(i.baseDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.Errorf("reading %q file: %v", path, err)
+		}
+		// Files mounted from secrets usually include a trailing new line
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if ref == "" {
+		return "", errors.New("empty environment variable name")
+	}
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", errors.Errorf("environment variable %q is not set", ref)
+	}
+	return v, nil
+}
+
+// tree replaces the references in all the string values of a decoded JSON
+// document. It returns the errors found in every value at once.
+func (i *interpolator) tree(field string, v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		return i.value(field, t)
+	case []interface{}:
+		var errs error
+		for n, e := range t {
+			r, err := i.tree(fmt.Sprintf("%s[%d]", field, n), e)
+			errs = goerrors.Join(errs, err)
+			t[n] = r
+		}
+		return t, errs
+	case map[string]interface{}:
+		// Sort the keys so errors are reported in a stable order
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var errs error
+		for _, k := range keys {
+			r, err := i.tree(strings.TrimPrefix(field+"."+k, "."), t[k])
+			errs = goerrors.Join(errs, err)
+			t[k] = r
+		}
+		return t, errs
+	default:
+		return v, nil
+	}
+}
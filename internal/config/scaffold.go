@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// probeTimeout is the maximum time to wait for a source repository to be probed
+const probeTimeout = 1 * time.Minute
+
+// ProbeResult describes what was found probing a source repository
+type ProbeResult struct {
+	// Kind is the kind of the repository
+	Kind api.Kind
+	// HelmIndex is whether the repository serves an index.yaml file
+	HelmIndex bool
+	// ChartsIndex is the reference of the OCI charts index artifact, if found
+	ChartsIndex string
+	// Charts is the number of charts found in the repository index
+	Charts int
+}
+
+// ProbeSource looks for an index.yaml file or an OCI charts index in the
+// source repository. If the repository kind is UNKNOWN, the kind is guessed
+// from what is found.
+func ProbeSource(repo *api.Repo, insecure bool) (*ProbeResult, error) {
+	kind := repo.GetKind()
+	if kind == api.Kind_LOCAL {
+		charts, err := filepath.Glob(filepath.Join(repo.GetPath(), "*.tgz"))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, err := os.Stat(repo.GetPath()); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &ProbeResult{Kind: kind, Charts: len(charts)}, nil
+	}
+
+	if kind != api.Kind_OCI {
+		klog.V(3).Infof("Looking for an index.yaml file in %q", repo.GetUrl())
+		index, err := utils.LoadIndexFromRepo(repo)
+		if err == nil {
+			if kind == api.Kind_UNKNOWN {
+				kind = api.Kind_HELM
+			}
+			return &ProbeResult{Kind: kind, HelmIndex: true, Charts: len(index.Entries)}, nil
+		}
+		if kind != api.Kind_UNKNOWN {
+			return nil, errors.Annotatef(err, "looking for an index.yaml file in %q", repo.GetUrl())
+		}
+		klog.V(3).Infof("index.yaml file not found: %v", err)
+	}
+
+	ref, err := defaultChartsIndex(repo.GetUrl())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	klog.V(3).Infof("Looking for a charts index in %q", ref)
+	opts := []indexer.OciIndexerOpt{
+		indexer.WithHost(repo.GetUrl()),
+		indexer.WithBasicAuth(repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword()),
+		indexer.WithIndexRef(ref),
+	}
+	if insecure {
+		opts = append(opts, indexer.WithInsecure())
+	}
+	ind, err := indexer.NewOciIndexer(opts...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	idx, err := ind.Get(ctx)
+	if err != nil {
+		// The registry answered, but there is no index
+		if indexer.IsNotFound(err) {
+			return &ProbeResult{Kind: api.Kind_OCI}, nil
+		}
+		return nil, errors.Annotatef(err, "unable to find an index.yaml file or a charts index in %q", repo.GetUrl())
+	}
+	return &ProbeResult{Kind: api.Kind_OCI, ChartsIndex: ref, Charts: len(idx.GetEntries())}, nil
+}
+
+// Scaffold describes a config file to be created
+type Scaffold struct {
+	Source *api.Repo
+	Target *api.Repo
+	// ContainersURL is the registry where the container images are pushed,
+	// if different to the target repository
+	ContainersURL      string
+	Charts             []string
+	SkipCharts         []string
+	ContainerPlatforms []string
+	// Probe contains what was found in the source repository, if it was probed
+	Probe *ProbeResult
+}
+
+// WriteScaffold writes a commented config file described by s
+func WriteScaffold(w io.Writer, s *Scaffold) error {
+	return errors.Trace(scaffoldTemplate.Execute(w, s))
+}
+
+var scaffoldTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	// quote returns a double quoted YAML string
+	"quote": func(s string) (string, error) {
+		b, err := json.Marshal(s)
+		return string(b), err
+	},
+}).Parse(`#
+# charts-syncer config file generated by "charts-syncer init"
+#
+# Run "charts-syncer config validate" to check it after editing it. Find all
+# the available keys at https://github.com/bitnami/charts-syncer
+#
+# Any value can reference environment variables with ${ENV_VAR} and files
+# with ${file:/path/to/file}
+#
+
+# source includes relevant information about the source chart repository
+source:
+  repo:
+    # kind of the source repository. Valid values are HELM, CHARTMUSEUM, HARBOR, OCI and LOCAL
+    kind: {{ .Source.Kind }}
+{{- if eq .Source.Kind.String "LOCAL" }}
+    # path is the directory containing the packaged charts
+    path: {{ quote .Source.Path }}
+{{- else }}
+    # url is the url of the chart repository
+    url: {{ quote .Source.Url }}
+    # auth is used if the source repository is protected with basic auth.
+    # SOURCE_REPO_AUTH_USERNAME and SOURCE_REPO_AUTH_PASSWORD env vars can be used instead
+    # auth:
+    #   username: ${SOURCE_REPO_USERNAME}
+    #   password: ${file:/path/to/source-password}
+{{- end }}
+{{- with .Probe }}
+{{- if .HelmIndex }}
+    # An index.yaml file listing {{ .Charts }} charts was found in the repository
+{{- else if .ChartsIndex }}
+    # A charts index listing {{ .Charts }} charts was found in the repository. It is used to
+    # discover the charts to sync
+    chartsIndex: {{ quote .ChartsIndex }}
+{{- else if eq .Kind.String "OCI" }}
+    # No charts index was found in the repository, so the charts to sync need to be listed in "charts"
+    disableChartsIndex: true
+{{- end }}
+{{- end }}
+
+# target includes relevant information about the target chart repository
+target:
+  repo:
+    # kind of the target repository. Valid values are OCI and LOCAL
+    kind: {{ .Target.Kind }}
+{{- if eq .Target.Kind.String "LOCAL" }}
+    # path is the directory where the charts are written
+    path: {{ quote .Target.Path }}
+{{- else }}
+    # url is the url of the chart repository
+    url: {{ quote .Target.Url }}
+    # auth is used if the target repository is protected with basic auth.
+    # TARGET_REPO_AUTH_USERNAME and TARGET_REPO_AUTH_PASSWORD env vars can be used instead
+    # auth:
+    #   username: ${TARGET_REPO_USERNAME}
+    #   password: ${file:/path/to/target-password}
+{{- end }}
+{{- if .ContainersURL }}
+  # containers configures the registry where the container images are pushed
+  containers:
+    url: {{ quote .ContainersURL }}
+{{- end }}
+
+# charts is an OPTIONAL list to specify a subset of charts to be synchronized.
+# It is mandatory if the source repository is OCI and does not have a charts index
+{{- if .Charts }}
+charts:
+{{- range .Charts }}
+  - {{ quote . }}
+{{- end }}
+{{- else }}
+# charts:
+#   - redis
+{{- end }}
+
+# skipCharts is the opt-out counterpart of "charts". Both can not be used at once
+{{- if .SkipCharts }}
+skipCharts:
+{{- range .SkipCharts }}
+  - {{ quote . }}
+{{- end }}
+{{- else }}
+# skipCharts:
+#   - mariadb
+{{- end }}
+
+# containerPlatforms is an OPTIONAL list of the container platforms to sync. All by default
+{{- if .ContainerPlatforms }}
+containerPlatforms:
+{{- range .ContainerPlatforms }}
+  - {{ quote . }}
+{{- end }}
+{{- else }}
+# containerPlatforms:
+#   - linux/amd64
+{{- end }}
+`))
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+)
+
+func TestWriteScaffold(t *testing.T) {
+	tests := []struct {
+		desc     string
+		scaffold *Scaffold
+		contains []string
+	}{
+		{
+			desc: "helm source",
+			scaffold: &Scaffold{
+				Source: &api.Repo{Kind: api.Kind_HELM, Url: "https://charts.bitnami.com/bitnami"},
+				Target: &api.Repo{Kind: api.Kind_OCI, Url: "https://registry.example.com/charts"},
+				Charts: []string{"redis", "mariadb"},
+				Probe:  &ProbeResult{Kind: api.Kind_HELM, HelmIndex: true, Charts: 2},
+			},
+			contains: []string{`url: "https://charts.bitnami.com/bitnami"`, `  - "redis"`, "# skipCharts:"},
+		},
+		{
+			desc: "oci source without charts index",
+			scaffold: &Scaffold{
+				Source:        &api.Repo{Kind: api.Kind_OCI, Url: "https://registry.example.com/bitnami"},
+				Target:        &api.Repo{Kind: api.Kind_LOCAL, Path: "/tmp/charts"},
+				ContainersURL: "https://images.example.com",
+				Charts:        []string{"redis"},
+				Probe:         &ProbeResult{Kind: api.Kind_OCI},
+			},
+			contains: []string{"disableChartsIndex: true", `path: "/tmp/charts"`, `url: "https://images.example.com"`},
+		},
+		{
+			desc: "oci source with charts index",
+			scaffold: &Scaffold{
+				Source:     &api.Repo{Kind: api.Kind_OCI, Url: "https://registry.example.com/bitnami"},
+				Target:     &api.Repo{Kind: api.Kind_OCI, Url: "https://registry.example.com/mirror"},
+				SkipCharts: []string{"kafka"},
+				Probe:      &ProbeResult{Kind: api.Kind_OCI, ChartsIndex: "registry.example.com/bitnami/charts-index:latest"},
+			},
+			contains: []string{`chartsIndex: "registry.example.com/bitnami/charts-index:latest"`, `  - "kafka"`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteScaffold(&buf, tc.scaffold); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.contains {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("%q not found in config file:\n%s", s, buf.String())
+				}
+			}
+
+			// The scaffolded config file must be valid
+			file := filepath.Join(t.TempDir(), "charts-syncer.yaml")
+			if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			problems, err := Validate(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) > 0 {
+				t.Errorf("unexpected problems in config file: %v\n%s", problems, buf.String())
+			}
+		})
+	}
+}
+
+func TestProbeSource(t *testing.T) {
+	index, err := os.ReadFile("../../testdata/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.yaml" {
+			w.Write(index)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer s.Close()
+
+	got, err := ProbeSource(&api.Repo{Url: s.URL}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != api.Kind_HELM || !got.HelmIndex || got.Charts == 0 {
+		t.Errorf("unexpected probe result: %+v", got)
+	}
+
+	dir := t.TempDir()
+	for _, f := range []string{"redis-1.0.0.tgz", "mariadb-1.0.0.tgz", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = ProbeSource(&api.Repo{Kind: api.Kind_LOCAL, Path: dir}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Charts != 2 {
+		t.Errorf("got %d charts, want 2", got.Charts)
+	}
+}
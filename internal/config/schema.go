@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/emicklei/proto"
+	"github.com/juju/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// schemaDraft is the JSON Schema version of the generated schemas
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema returns the JSON Schema of the config file, derived from the
+// api.Config proto definition. It can be used by editors to validate and
+// autocomplete config files.
+func Schema() ([]byte, error) {
+	docs, err := protoComments(api.ConfigProto)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	md := (&api.Config{}).ProtoReflect().Descriptor()
+	g := &schemaGenerator{docs: docs, definitions: make(map[string]interface{})}
+	schema := g.messageSchema(md)
+	schema["$schema"] = schemaDraft
+	schema["title"] = "charts-syncer config file"
+	schema["definitions"] = g.definitions
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaGenerator generates the schemas of the proto messages
+type schemaGenerator struct {
+	// docs are the comments of the messages and fields, by full name
+	docs map[string]string
+	// definitions are the schemas of the nested messages, by full name
+	definitions map[string]interface{}
+}
+
+// messageSchema returns the schema of a message, adding the schemas of the
+// nested messages to the definitions
+func (g *schemaGenerator) messageSchema(md protoreflect.MessageDescriptor) map[string]interface{} {
+	properties := make(map[string]interface{})
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		var s map[string]interface{}
+		switch {
+		case fd.IsMap():
+			s = map[string]interface{}{"type": "object", "additionalProperties": g.fieldSchema(fd.MapValue())}
+		case fd.IsList():
+			s = map[string]interface{}{"type": "array", "items": g.fieldSchema(fd)}
+		default:
+			s = g.fieldSchema(fd)
+		}
+		if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDeprecated() {
+			s["deprecated"] = true
+		}
+		if doc := g.docs[string(fd.FullName())]; doc != "" {
+			s["description"] = doc
+		}
+		// The loader accepts the proto field names too, i.e "charts_index"
+		properties[fd.JSONName()] = s
+		properties[fd.TextName()] = s
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if doc := g.docs[string(md.FullName())]; doc != "" {
+		schema["description"] = doc
+	}
+	return schema
+}
+
+func (g *schemaGenerator) fieldSchema(fd protoreflect.FieldDescriptor) map[string]interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		name := string(fd.Message().FullName())
+		if _, ok := g.definitions[name]; !ok {
+			// Reserve the name to support recursive messages
+			g.definitions[name] = nil
+			g.definitions[name] = g.messageSchema(fd.Message())
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names[i] = string(values.Get(i).Name())
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// protoComments returns the comments of the messages and fields of a proto
+// file, by full name, i.e "api.Config.source"
+func protoComments(def string) (map[string]string, error) {
+	p, err := proto.NewParser(strings.NewReader(def)).Parse()
+	if err != nil {
+		return nil, errors.Annotatef(err, "parsing the config proto definition")
+	}
+	docs := make(map[string]string)
+	var pkg string
+	var walk func(prefix string, elements []proto.Visitee)
+	walk = func(prefix string, elements []proto.Visitee) {
+		for _, e := range elements {
+			switch e := e.(type) {
+			case *proto.Package:
+				pkg = e.Name
+			case *proto.Message:
+				name := prefix + e.Name
+				if prefix == "" && pkg != "" {
+					name = pkg + "." + e.Name
+				}
+				docs[name] = commentText(e.Comment)
+				walk(name+".", e.Elements)
+			case *proto.NormalField:
+				docs[prefix+e.Name] = commentText(e.Comment)
+			case *proto.MapField:
+				docs[prefix+e.Name] = commentText(e.Comment)
+			}
+		}
+	}
+	walk("", p.Elements)
+	return docs, nil
+}
+
+// commentText returns the lines of a comment joined in a single one
+func commentText(c *proto.Comment) string {
+	if c == nil {
+		return ""
+	}
+	lines := make([]string, 0, len(c.Lines))
+	for _, l := range c.Lines {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, " ")
+}
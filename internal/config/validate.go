@@ -0,0 +1,459 @@
+package config
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/schedule"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/juju/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
+)
+
+// reachabilityTimeout is the maximum time to wait for a repository to answer
+const reachabilityTimeout = 10 * time.Second
+
+// deprecationHints contains hints about how to replace deprecated fields,
+// indexed by the proto field full name
+var deprecationHints = map[protoreflect.FullName]string{
+	"api.Repo.use_charts_index": `Charts indexes are used by default. Use "disableChartsIndex" to opt-out`,
+}
+
+var yamlLineRE = regexp.MustCompile(`line (\d+)`)
+
+// ValidateAllowedWindows checks the schedules of the windows when the charts
+// can be pushed to the targets. It complements api.Config.Validate, as the
+// schedules are parsed by an internal package.
+func ValidateAllowedWindows(c *api.Config) error {
+	var errs error
+	for i, w := range c.GetAllowedWindows() {
+		field := fmt.Sprintf("allowedWindows[%d]", i)
+		if _, err := schedule.NewWindow(w.GetSchedule(), w.GetDuration(), w.GetTimeZone()); err != nil {
+			errs = goerrors.Join(errs, &api.FieldError{Field: field, Message: fmt.Sprintf(`"%s" is not a valid window: %v`, field, err)})
+		}
+	}
+	return errs
+}
+
+// Problem describes an issue found in a config file
+type Problem struct {
+	// Field is the path of the field in the config file, i.e "target.repo.url"
+	Field string
+	// Line is the line of the field in the config file. Zero if unknown
+	Line    int
+	Message string
+	// Warning problems do not make the config file invalid
+	Warning bool
+}
+
+func (p Problem) String() string {
+	severity := "error"
+	if p.Warning {
+		severity = "warning"
+	}
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", p.Line, severity, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", severity, p.Message)
+}
+
+type validateOpts struct {
+	checkReachability bool
+	insecure          bool
+}
+
+// ValidateOption is an option value used to validate a config file
+type ValidateOption func(*validateOpts)
+
+// WithReachabilityChecks configures the validation to check the source and
+// target repositories are reachable
+func WithReachabilityChecks(enable bool) ValidateOption {
+	return func(o *validateOpts) {
+		o.checkReachability = enable
+	}
+}
+
+// WithInsecure configures the reachability checks to allow insecure SSL
+// connections
+func WithInsecure(enable bool) ValidateOption {
+	return func(o *validateOpts) {
+		o.insecure = enable
+	}
+}
+
+// Validate checks the config file in path and returns all the problems found
+// sorted by line. An error is only returned if the file can not be read.
+func Validate(path string, opts ...ValidateOption) ([]Problem, error) {
+	vopts := &validateOpts{}
+	for _, o := range opts {
+		o(vopts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		p := Problem{Message: err.Error()}
+		if m := yamlLineRE.FindStringSubmatch(err.Error()); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+		}
+		return []Problem{p}, nil
+	}
+
+	// Look for unknown fields, wrong types and deprecated fields
+	w := &walker{lines: make(map[string]int), invalid: make(map[*yaml.Node]bool)}
+	if len(doc.Content) > 0 {
+		w.message(doc.Content[0], "", (&api.Config{}).ProtoReflect().Descriptor())
+	}
+	problems := w.problems
+
+	// The fields with schema problems are dropped, so the rest of the config
+	// file is checked anyway
+	if hasErrors(problems) {
+		if len(doc.Content) == 0 || w.prune(doc.Content[0]) {
+			return sortProblems(problems), nil
+		}
+		if data, err = yaml.Marshal(&doc); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	var c api.Config
+	err = yamlBytesToProto(data, filepath.Dir(path), &c)
+	if err == nil {
+		err = goerrors.Join(c.Validate(), ValidateAllowedWindows(&c))
+	}
+	for _, e := range flattenErrors(err) {
+		p := Problem{Message: e.Error()}
+		var fe *api.FieldError
+		if goerrors.As(e, &fe) {
+			// Dropped fields are reported already
+			if w.dropped(fe.Field) {
+				continue
+			}
+			p.Field = fe.Field
+			p.Line = w.line(fe.Field)
+		}
+		problems = append(problems, p)
+	}
+
+	if vopts.checkReachability && !hasErrors(problems) {
+		for i, s := range c.AllSources() {
+			field := "source"
+			if i > 0 {
+				field = fmt.Sprintf("sources[%d]", i-1)
+			}
+			problems = append(problems, w.checkReachability(field, s.GetRepo(), true, vopts.insecure)...)
+		}
+		for i, t := range c.AllTargets() {
+			field := "target"
+			if i > 0 {
+				field = fmt.Sprintf("targets[%d]", i-1)
+			}
+			problems = append(problems, w.checkReachability(field, t.GetRepo(), false, vopts.insecure)...)
+		}
+	}
+
+	return sortProblems(problems), nil
+}
+
+// checkReachability checks the repo is reachable. Local repos are only
+// checked if they are sources, as targets are created on demand.
+func (w *walker) checkReachability(field string, repo *api.Repo, source, insecure bool) []Problem {
+	if repo == nil {
+		return nil
+	}
+	if repo.GetKind() == api.Kind_LOCAL {
+		if _, err := os.Stat(repo.GetPath()); source && err != nil {
+			field = field + ".repo.path"
+			return []Problem{{Field: field, Line: w.line(field), Message: fmt.Sprintf("%q is not reachable: %v", repo.GetPath(), err)}}
+		}
+		return nil
+	}
+
+	field = field + ".repo.url"
+	u, err := url.Parse(repo.GetUrl())
+	if err != nil {
+		// Already reported by the config validation
+		return nil
+	}
+	if repo.GetKind() == api.Kind_OCI {
+		u.Path = "/v2/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reachabilityTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return []Problem{{Field: field, Line: w.line(field), Message: err.Error()}}
+	}
+	client := utils.DefaultClient
+	if insecure {
+		client = utils.InsecureClient
+	}
+	// Any answer, even an authentication error, means the repo is reachable
+	res, err := client.Do(req)
+	if err != nil {
+		return []Problem{{Field: field, Line: w.line(field), Message: fmt.Sprintf("%q is not reachable: %v", repo.GetUrl(), err)}}
+	}
+	res.Body.Close()
+	return nil
+}
+
+// walker walks a YAML document checking it against the config proto
+// definition. It also records the line of every field found, and the nodes
+// with problems.
+type walker struct {
+	lines    map[string]int
+	problems []Problem
+	invalid  map[*yaml.Node]bool
+}
+
+func (w *walker) add(n *yaml.Node, field string, warning bool, format string, args ...interface{}) {
+	w.problems = append(w.problems, Problem{Field: field, Line: n.Line, Message: fmt.Sprintf(format, args...), Warning: warning})
+	if !warning {
+		w.invalid[n] = true
+	}
+}
+
+// prune removes the fields with problems from the document. Lists are
+// removed as a whole if any of their elements has problems, so the indexes of
+// the remaining fields do not change. It returns whether n itself has to be
+// removed.
+func (w *walker) prune(n *yaml.Node) bool {
+	n = resolveAlias(n)
+	if w.invalid[n] {
+		return true
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if w.invalid[k] || w.prune(v) {
+				continue
+			}
+			content = append(content, k, v)
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		for _, e := range n.Content {
+			if w.prune(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dropped returns whether field, or any of its parents, has problems
+func (w *walker) dropped(field string) bool {
+	for _, p := range w.problems {
+		if p.Warning || p.Field == "" {
+			continue
+		}
+		f := p.Field
+		// Lists are dropped as a whole
+		if strings.HasSuffix(f, "]") {
+			f = f[:strings.LastIndex(f, "[")]
+		}
+		if field == f || strings.HasPrefix(field, f+".") || strings.HasPrefix(field, f+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// line returns the line of field, or of its closest parent if the field is
+// not in the document
+func (w *walker) line(field string) int {
+	for field != "" {
+		if l, ok := w.lines[field]; ok {
+			return l
+		}
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return 0
+}
+
+func (w *walker) message(n *yaml.Node, field string, md protoreflect.MessageDescriptor) {
+	n = resolveAlias(n)
+	if isNull(n) {
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		w.add(n, field, false, "%q should be an object", field)
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		fd := md.Fields().ByJSONName(k.Value)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(k.Value))
+		}
+		if fd == nil {
+			w.add(k, joinField(field, k.Value), false, "unknown field %q", joinField(field, k.Value))
+			continue
+		}
+
+		f := joinField(field, fd.JSONName())
+		w.lines[f] = k.Line
+		if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDeprecated() {
+			msg := fmt.Sprintf("%q is deprecated", f)
+			if hint, ok := deprecationHints[fd.FullName()]; ok {
+				msg = fmt.Sprintf("%s. %s", msg, hint)
+			}
+			w.add(k, f, true, "%s", msg)
+		}
+		w.field(v, f, fd)
+	}
+}
+
+func (w *walker) field(n *yaml.Node, field string, fd protoreflect.FieldDescriptor) {
+	n = resolveAlias(n)
+	if isNull(n) {
+		return
+	}
+	if fd.IsMap() {
+		if n.Kind != yaml.MappingNode {
+			w.add(n, field, false, "%q should be an object", field)
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			f := joinField(field, k.Value)
+			w.lines[f] = k.Line
+			w.value(v, f, fd.MapValue())
+		}
+		return
+	}
+	if !fd.IsList() {
+		w.value(n, field, fd)
+		return
+	}
+	if n.Kind != yaml.SequenceNode {
+		w.add(n, field, false, "%q should be a list", field)
+		return
+	}
+	for i, e := range n.Content {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		w.lines[f] = e.Line
+		w.value(e, f, fd)
+	}
+}
+
+func (w *walker) value(n *yaml.Node, field string, fd protoreflect.FieldDescriptor) {
+	n = resolveAlias(n)
+	if fd.Kind() == protoreflect.MessageKind {
+		w.message(n, field, fd.Message())
+		return
+	}
+	if n.Kind != yaml.ScalarNode {
+		w.add(n, field, false, "%q should be a %s", field, kindName(fd))
+		return
+	}
+
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		if values.ByName(protoreflect.Name(n.Value)) == nil && n.ShortTag() != "!!int" {
+			names := make([]string, values.Len())
+			for i := 0; i < values.Len(); i++ {
+				names[i] = fmt.Sprintf("%q", values.Get(i).Name())
+			}
+			w.add(n, field, false, "%q should be one of %s", field, strings.Join(names, ", "))
+		}
+	case protoreflect.BoolKind:
+		if n.ShortTag() != "!!bool" {
+			w.add(n, field, false, "%q should be a boolean", field)
+		}
+	case protoreflect.StringKind:
+		if n.ShortTag() != "!!str" {
+			w.add(n, field, false, "%q should be a string. Quote the value", field)
+		}
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		if n.ShortTag() != "!!int" {
+			w.add(n, field, false, "%q should be an integer", field)
+		}
+	}
+}
+
+func kindName(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "boolean"
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return "integer"
+	default:
+		return "string"
+	}
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
+
+// flattenErrors returns the list of errors joined in err
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range j.Unwrap() {
+			errs = append(errs, flattenErrors(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func hasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+func sortProblems(problems []Problem) []Problem {
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems
+}
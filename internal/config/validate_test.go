@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc   string
+		config string
+		want   []Problem
+	}{
+		{
+			desc: "valid config",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: https://charts.example.com
+target:
+  repo:
+    kind: OCI
+    url: https://registry.example.com
+`,
+		},
+		{
+			desc: "schema problems",
+			config: `
+source:
+  repo:
+    kind: HELMS
+    url: https://charts.example.com
+    useChartsIndex: true
+    unknown: foo
+target:
+  repo:
+    kind: OCI
+    url: https://registry.example.com
+    auth:
+      username: 1234
+charts: redis
+`,
+			want: []Problem{
+				{Field: "source.repo.kind", Line: 4, Message: `"source.repo.kind" should be one of "UNKNOWN", "HELM", "CHARTMUSEUM", "HARBOR", "OCI", "LOCAL"`},
+				{
+					Field: "source.repo.useChartsIndex", Line: 6, Warning: true,
+					Message: `"source.repo.useChartsIndex" is deprecated. Charts indexes are used by default. Use "disableChartsIndex" to opt-out`,
+				},
+				{Field: "source.repo.unknown", Line: 7, Message: `unknown field "source.repo.unknown"`},
+				{Field: "target.repo.auth.username", Line: 13, Message: `"target.repo.auth.username" should be a string. Quote the value`},
+				{Field: "charts", Line: 14, Message: `"charts" should be a list`},
+			},
+		},
+		{
+			desc: "semantic problems",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: charts.example.com
+target:
+  repo:
+    kind: HELM
+    url: https://registry.example.com
+chartMappings:
+  - charts: ["kafka*"]
+    name: events
+`,
+			want: []Problem{
+				{Field: "source.repo.url", Line: 5, Message: `"source.repo.url" should be a valid URL: parse "charts.example.com": invalid URI for request`},
+				{Field: "target.repo.kind", Line: 8, Message: `"target.repo.kind" should be "OCI" or "LOCAL"`},
+				{Field: "chartMappings[0].name", Line: 12, Message: `"chartMappings[0].name" requires "charts" to contain a single chart name`},
+			},
+		},
+		{
+			desc: "schema and semantic problems",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: charts.example.com
+    unknown: foo
+target:
+  repo:
+    kind: HELMS
+    url: https://registry.example.com
+`,
+			want: []Problem{
+				{Field: "source.repo.url", Line: 5, Message: `"source.repo.url" should be a valid URL: parse "charts.example.com": invalid URI for request`},
+				{Field: "source.repo.unknown", Line: 6, Message: `unknown field "source.repo.unknown"`},
+				{Field: "target.repo.kind", Line: 9, Message: `"target.repo.kind" should be one of "UNKNOWN", "HELM", "CHARTMUSEUM", "HARBOR", "OCI", "LOCAL"`},
+			},
+		},
+		{
+			desc: "map problems",
+			config: `
+source:
+  repo:
+    kind: HELM
+    url: https://charts.example.com
+target:
+  repo:
+    kind: OCI
+    url: https://registry.example.com
+transform:
+  annotations:
+    example.com/team: platform
+    example.com/replicas: 3
+`,
+			want: []Problem{
+				{Field: "transform.annotations.example.com/replicas", Line: 13, Message: `"transform.annotations.example.com/replicas" should be a string. Quote the value`},
+			},
+		},
+		{
+			desc:   "syntax error",
+			config: "source:\n  repo: [\n",
+			want:   []Problem{{Line: 2, Message: "yaml: line 2: did not find expected node content"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfgFile := filepath.Join(t.TempDir(), "charts-syncer.yaml")
+			if err := os.WriteFile(cfgFile, []byte(tc.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Validate(cfgFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got:\n%+v\nwant:\n%+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Properties  map[string]interface{}            `json:"properties"`
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"source", "target", "charts", "skipCharts", "chartMappings"} {
+		if _, ok := schema.Properties[p]; !ok {
+			t.Errorf("%q property not found in the schema", p)
+		}
+	}
+	repo := schema.Definitions["api.Repo"]["properties"].(map[string]interface{})
+	if got := repo["useChartsIndex"].(map[string]interface{})["deprecated"]; got != true {
+		t.Errorf(`got "useChartsIndex" deprecated %v, want true`, got)
+	}
+	if got, want := repo["kind"].(map[string]interface{})["enum"], []interface{}{"UNKNOWN", "HELM", "CHARTMUSEUM", "HARBOR", "OCI", "LOCAL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got kind enum %v, want %v", got, want)
+	}
+	transform := schema.Definitions["api.Transform"]["properties"].(map[string]interface{})
+	if got, want := transform["annotations"], map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}, "description": "Annotations added to Chart.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got annotations schema %v, want %v", got, want)
+	}
+
+	// The proto field names are accepted too
+	for _, p := range []string{"charts_index", "disable_charts_index"} {
+		if _, ok := repo[p]; !ok {
+			t.Errorf("%q property not found in the repo schema", p)
+		}
+	}
+	// The proto comments document the schema
+	if got, want := repo["chartsIndex"].(map[string]interface{})["description"], "The OCI reference where the index of charts is located Example: my.oci.domain/index:latest"; !strings.HasPrefix(got.(string), want) {
+		t.Errorf("got chartsIndex description %q, want it to start with %q", got, want)
+	}
+	if got, want := schema.Definitions["api.Containers.ContainerAuth"]["description"], "ContainerAuth defines the authentication parameters required to access the source/target OCI registries"; got != want {
+		t.Errorf("got ContainerAuth description %q, want %q", got, want)
+	}
+}
+
+func TestValidateAllowedWindows(t *testing.T) {
+	config := &api.Config{
+		Source: &api.Source{
+			Repo: &api.Repo{Url: "http://fake.source.com", Kind: api.Kind_HELM},
+		},
+		Target: &api.Target{
+			Repo: &api.Repo{Url: "http://fake.target.com", Kind: api.Kind_OCI},
+		},
+		AllowedWindows: []*api.AllowedWindow{
+			{Schedule: "0 22 * * MON-FRI", Duration: "2h", TimeZone: "Europe/Madrid"},
+			{Schedule: "0 22 * *", Duration: "2h"},
+			{Schedule: "0 22 * * *", Duration: "2 hours"},
+		},
+	}
+
+	err := ValidateAllowedWindows(config)
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{
+		`"allowedWindows[1]" is not a valid window: "0 22 * *" should have 5 fields`,
+		`"allowedWindows[2]" is not a valid window: invalid duration "2 hours"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "allowedWindows[0]") {
+		t.Errorf("got error %q, want no error about valid windows", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package discovery implements helpers to find the container images used by
+// charts not listing them in their "images" annotation
+package discovery
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/chartutils"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/klog"
+)
+
+// containerKeys are the keys of the pod specs holding containers
+var containerKeys = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// IsAnnotated returns whether the chart lists its images in the "images"
+// annotation
+func IsAnnotated(c *chart.Chart) bool {
+	_, ok := c.Metadata.Annotations[imagelock.DefaultAnnotationsKey]
+	return ok
+}
+
+// Images returns the container images used by a chart, found with the
+// provided methods. Images already listed in the annotations of the
+// dependencies are not included.
+func Images(c *chart.Chart, methods ...api.ImageDiscovery) ([]string, error) {
+	found := make(map[string]struct{})
+	for _, m := range methods {
+		var images []string
+		switch m {
+		case api.ImageDiscovery_VALUES:
+			images = fromValues(c)
+		case api.ImageDiscovery_TEMPLATES:
+			var err error
+			if images, err = fromTemplates(c); err != nil {
+				return nil, errors.Annotatef(err, "rendering %q chart templates", c.Name())
+			}
+		default:
+			return nil, errors.NotSupportedf("%q image discovery", m)
+		}
+		for _, img := range images {
+			found[img] = struct{}{}
+		}
+	}
+
+	// The images of the annotated dependencies are already synced
+	for _, img := range annotatedImages(c) {
+		delete(found, img)
+	}
+
+	images := make([]string, 0, len(found))
+	for img := range found {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// Annotation returns the "images" annotation listing the provided images
+func Annotation(images []string) (string, error) {
+	var list imagelock.ImageList
+	for _, img := range images {
+		ref, err := name.ParseReference(img)
+		if err != nil {
+			return "", errors.Annotatef(err, "parsing %q image", img)
+		}
+		list = append(list, &imagelock.ChartImage{Name: path.Base(ref.Context().RepositoryStr()), Image: img})
+	}
+	data, err := list.ToAnnotation()
+	return string(data), errors.Trace(err)
+}
+
+// fromValues returns the images defined as registry, repository and tag
+// objects in the values of the chart and its non-annotated dependencies
+func fromValues(c *chart.Chart) []string {
+	var images []string
+	elements, _ := chartutils.FindImageElementsInValuesMap(c.Values)
+	for _, e := range elements {
+		if strings.Contains(e.URL(), "{{") {
+			continue
+		}
+		// Charts usually default to the app version when the tag is empty
+		if e.Tag == "" && e.Digest == "" && c.AppVersion() != "" {
+			e.Tag = c.AppVersion()
+		}
+		images = append(images, e.URL())
+	}
+	for _, dep := range c.Dependencies() {
+		if !IsAnnotated(dep) {
+			images = append(images, fromValues(dep)...)
+		}
+	}
+	return images
+}
+
+// fromTemplates returns the images of the containers found in the chart
+// templates, rendered with the default values
+func fromTemplates(c *chart.Chart) ([]string, error) {
+	values, err := chartutil.ToRenderValues(c, map[string]interface{}{}, chartutil.ReleaseOptions{
+		Name:      c.Name(),
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Lint mode does not fail on missing required values
+	rendered, err := engine.Engine{LintMode: true}.Render(c, values)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var images []string
+	for file, content := range rendered {
+		if ext := path.Ext(file); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		d := yaml.NewDecoder(bytes.NewBufferString(content))
+		for {
+			var doc interface{}
+			if err := d.Decode(&doc); err == io.EOF {
+				break
+			} else if err != nil {
+				klog.V(4).Infof("unable to parse %q rendered template: %v", file, err)
+				break
+			}
+			images = append(images, containerImages(doc)...)
+		}
+	}
+	return images, nil
+}
+
+// containerImages returns the images of the containers in a Kubernetes object
+func containerImages(obj interface{}) []string {
+	var images []string
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range o {
+			for _, key := range containerKeys {
+				if k != key {
+					continue
+				}
+				containers, _ := v.([]interface{})
+				for _, c := range containers {
+					c, _ := c.(map[string]interface{})
+					if img, ok := c["image"].(string); ok && strings.TrimSpace(img) != "" {
+						images = append(images, strings.TrimSpace(img))
+					}
+				}
+			}
+			images = append(images, containerImages(v)...)
+		}
+	case []interface{}:
+		for _, v := range o {
+			images = append(images, containerImages(v)...)
+		}
+	}
+	return images
+}
+
+// annotatedImages returns the images listed in the annotations of the chart
+// dependencies
+func annotatedImages(c *chart.Chart) []string {
+	var images []string
+	for _, dep := range c.Dependencies() {
+		list, err := imagelock.GetImagesFromChartAnnotations(dep, imagelock.NewImagesLockConfig())
+		if err != nil {
+			klog.V(4).Infof("unable to read %q chart images annotation: %v", dep.Name(), err)
+		}
+		for _, img := range list {
+			images = append(images, img.Image)
+		}
+		images = append(images, annotatedImages(dep)...)
+	}
+	return images
+}
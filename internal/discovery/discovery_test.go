@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestImages(t *testing.T) {
+	c, err := loader.Load("../../testdata/apache-7.3.15.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsAnnotated(c) {
+		t.Fatal("expected a chart without images annotation")
+	}
+
+	testCases := []struct {
+		desc    string
+		methods []api.ImageDiscovery
+		want    []string
+	}{
+		{
+			desc:    "values",
+			methods: []api.ImageDiscovery{api.ImageDiscovery_VALUES},
+			want: []string{
+				"docker.io/bitnami/apache-exporter:0.8.0-debian-10-r17",
+				"docker.io/bitnami/apache:2.4.43-debian-10-r25",
+				"docker.io/bitnami/git:2.26.2-debian-10-r1",
+			},
+		},
+		{
+			// Metrics and git containers are disabled by default
+			desc:    "templates",
+			methods: []api.ImageDiscovery{api.ImageDiscovery_TEMPLATES},
+			want:    []string{"docker.io/bitnami/apache:2.4.43-debian-10-r25"},
+		},
+		{
+			desc:    "values and templates",
+			methods: []api.ImageDiscovery{api.ImageDiscovery_TEMPLATES, api.ImageDiscovery_VALUES},
+			want: []string{
+				"docker.io/bitnami/apache-exporter:0.8.0-debian-10-r17",
+				"docker.io/bitnami/apache:2.4.43-debian-10-r25",
+				"docker.io/bitnami/git:2.26.2-debian-10-r1",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := Images(c, tc.methods...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got images: %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAnnotation(t *testing.T) {
+	got, err := Annotation([]string{"docker.io/bitnami/apache:2.4.43-debian-10-r25", "nginx:1.25"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- name: apache\n  image: docker.io/bitnami/apache:2.4.43-debian-10-r25\n",
+		"- name: nginx\n  image: nginx:1.25\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got annotation %q, want it to contain %q", got, want)
+		}
+	}
+
+	if _, err := Annotation([]string{"INVALID:image"}); err == nil {
+		t.Error("expected an error for an invalid image")
+	}
+}
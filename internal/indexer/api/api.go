@@ -14,3 +14,22 @@ func (x *Index) Has(name, version string) bool {
 	}
 	return false
 }
+
+// Add adds a chart version to the index, replacing it if it is already indexed
+func (x *Index) Add(c *ChartMetadata) {
+	if x.Entries == nil {
+		x.Entries = make(map[string]*Index_ChartEntries)
+	}
+	entries := x.Entries[c.GetName()]
+	if entries == nil {
+		entries = &Index_ChartEntries{}
+		x.Entries[c.GetName()] = entries
+	}
+	for i, v := range entries.GetVersions() {
+		if v.GetVersion() == c.GetVersion() {
+			entries.Versions[i] = c
+			return
+		}
+	}
+	entries.Versions = append(entries.Versions, c)
+}
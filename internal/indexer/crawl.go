@@ -0,0 +1,196 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/internal/indexer/api"
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// helmChartConfigMediaType is the media type of the config of Helm charts
+// stored in OCI registries
+const helmChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+
+// helmChartLayerMediaTypes are the media types of the content layer of Helm
+// charts stored in OCI registries
+var helmChartLayerMediaTypes = []string{
+	"application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+	"application/tar+gzip",
+}
+
+// catalogPageSize is the number of repositories requested per catalog page.
+// Some registries reject bigger pages.
+const catalogPageSize = 100
+
+// Crawl builds an index from scratch with all the charts published under the
+// OCI host URL. The repositories are discovered from the registry catalog, so
+// the registry needs to support the catalog API.
+func Crawl(ctx context.Context, opts ...OciIndexerOpt) (*api.Index, error) {
+	c, err := newCatalog(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	names, err := c.repositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &api.Index{ApiVersion: "v1"}
+	for _, chartName := range names {
+		r, err := name.NewRepository(path.Join(c.host, c.prefix, chartName), c.nameOpts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid repository %q", chartName)
+		}
+		tags, err := remote.List(r, c.remoteOpts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list the tags of %q", r.RepositoryStr())
+		}
+		for _, tag := range tags {
+			m, err := chartMetadata(r.Tag(tag), c.remoteOpts)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to inspect %s:%s", r.RepositoryStr(), tag)
+			}
+			if m == nil {
+				klog.V(5).Infof("Skipping %s:%s as it is not a chart", r.RepositoryStr(), tag)
+				continue
+			}
+			m.Name = chartName
+			m.Urls = []string{fmt.Sprintf("%s:%s", r.Name(), tag)}
+			idx.Add(m)
+		}
+	}
+	return idx, nil
+}
+
+// Repositories returns the names, relative to the path of the OCI host URL,
+// of the repositories found in the registry catalog. The registry needs to
+// support the catalog API.
+func Repositories(ctx context.Context, opts ...OciIndexerOpt) ([]string, error) {
+	c, err := newCatalog(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.repositories(ctx)
+}
+
+// catalog lists the repositories of a registry under a path
+type catalog struct {
+	host       string
+	prefix     string
+	reg        name.Registry
+	nameOpts   []name.Option
+	remoteOpts []remote.Option
+}
+
+func newCatalog(ctx context.Context, opts ...OciIndexerOpt) (*catalog, error) {
+	opt := &ociIndexerOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	u, err := url.Parse(opt.url)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidArgument, "invalid OCI host URL: %+v", err)
+	}
+	c := &catalog{host: u.Host, prefix: strings.Trim(u.Path, "/")}
+	if u.Scheme == "http" {
+		c.nameOpts = append(c.nameOpts, name.Insecure)
+	}
+	if c.reg, err = name.NewRegistry(u.Host, c.nameOpts...); err != nil {
+		return nil, errors.Wrapf(ErrInvalidArgument, "invalid OCI host: %+v", err)
+	}
+
+	c.remoteOpts = []remote.Option{remote.WithContext(ctx)}
+	if opt.username != "" && opt.password != "" {
+		c.remoteOpts = append(c.remoteOpts, remote.WithAuth(&authn.Basic{Username: opt.username, Password: opt.password}))
+	}
+	c.remoteOpts = append(c.remoteOpts, remote.WithTransport(throttle.RegistryTransport(opt.insecure)))
+	return c, nil
+}
+
+// repositories returns the sorted names of the repositories under the
+// catalog prefix, relative to it
+func (c *catalog) repositories(ctx context.Context) ([]string, error) {
+	repos, err := remote.Catalog(ctx, c.reg, append(c.remoteOpts, remote.WithPageSize(catalogPageSize))...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list the repositories of %q", c.host)
+	}
+	sort.Strings(repos)
+
+	var names []string
+	for _, repo := range repos {
+		if c.prefix == "" {
+			names = append(names, repo)
+		} else if n, ok := strings.CutPrefix(repo, c.prefix+"/"); ok {
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+// chartMetadata returns the metadata of the chart in ref, or nil if ref is not
+// a chart
+func chartMetadata(ref name.Tag, opts []remote.Option) (*api.ChartMetadata, error) {
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(desc.Manifest, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Config.MediaType != helmChartConfigMediaType {
+		return nil, nil
+	}
+
+	c := &api.ChartMetadata{ReleasedAt: manifest.Annotations[ocispec.AnnotationCreated]}
+	for _, l := range manifest.Layers {
+		for _, t := range helmChartLayerMediaTypes {
+			if l.MediaType == t {
+				c.Digest = l.Digest.String()
+			}
+		}
+	}
+
+	// The config contains the Chart.yaml metadata
+	layer, err := remote.Layer(ref.Context().Digest(manifest.Config.Digest.String()), opts...)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Version     string            `json:"version"`
+		AppVersion  string            `json:"appVersion"`
+		Deprecated  bool              `json:"deprecated"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	c.Version = config.Version
+	c.AppVersion = config.AppVersion
+	c.Deprecated = config.Deprecated
+	c.Annotations = config.Annotations
+	return c, nil
+}
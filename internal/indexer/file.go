@@ -0,0 +1,34 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/internal/indexer/api"
+	"github.com/pkg/errors"
+)
+
+// fileIndexer is an Indexer reading the index from a local file
+type fileIndexer struct {
+	path string
+}
+
+// newFileIndexer returns an indexer reading the index from the file of a
+// file:// reference. Relative paths, like file://index.yaml, are relative to
+// the working directory.
+func newFileIndexer(reference string) *fileIndexer {
+	return &fileIndexer{path: strings.TrimPrefix(reference, fileScheme)}
+}
+
+// Get implements Indexer
+func (ind *fileIndexer) Get(_ context.Context) (*api.Index, error) {
+	data, err := os.ReadFile(ind.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrap(ErrNotFound, err.Error())
+		}
+		return nil, errors.Wrapf(err, "unable to read index file")
+	}
+	return parseIndex(data)
+}
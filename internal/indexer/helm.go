@@ -0,0 +1,58 @@
+package indexer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/internal/indexer/api"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	helmrepo "helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// parseIndex parses a charts index, or a Helm index.yaml file
+func parseIndex(data []byte) (*api.Index, error) {
+	idx := &api.Index{}
+	u := protojson.UnmarshalOptions{DiscardUnknown: true}
+	indexErr := u.Unmarshal(data, idx)
+	if indexErr == nil {
+		return idx, nil
+	}
+
+	helmIdx := &helmrepo.IndexFile{}
+	if err := yaml.Unmarshal(data, helmIdx); err != nil || helmIdx.APIVersion == "" {
+		return nil, errors.Wrapf(indexErr, "unable to parse index file as a charts index or a Helm index")
+	}
+	return fromHelmIndex(helmIdx), nil
+}
+
+// fromHelmIndex converts a Helm index into a charts index
+func fromHelmIndex(helmIdx *helmrepo.IndexFile) *api.Index {
+	idx := &api.Index{ApiVersion: helmIdx.APIVersion}
+	for name, versions := range helmIdx.Entries {
+		for _, cv := range versions {
+			if cv == nil || cv.Metadata == nil {
+				continue
+			}
+			c := &api.ChartMetadata{
+				Name:        name,
+				Version:     cv.Version,
+				AppVersion:  cv.AppVersion,
+				Urls:        cv.URLs,
+				Digest:      cv.Digest,
+				Deprecated:  cv.Deprecated,
+				Annotations: cv.Annotations,
+			}
+			// Helm indexes record the hex encoded SHA256 of the chart package
+			if c.Digest != "" && !strings.Contains(c.Digest, ":") {
+				c.Digest = "sha256:" + c.Digest
+			}
+			if !cv.Created.IsZero() {
+				c.ReleasedAt = cv.Created.UTC().Format(time.RFC3339)
+			}
+			idx.Add(c)
+		}
+	}
+	return idx
+}
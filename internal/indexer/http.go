@@ -0,0 +1,67 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/bitnami/charts-syncer/internal/indexer/api"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/pkg/errors"
+)
+
+// httpIndexer is an Indexer downloading the index from an HTTP URL
+type httpIndexer struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newHTTPIndexer returns an indexer downloading the index from an http:// or
+// https:// reference. The credentials are only sent to the repository host, so
+// they are not leaked to indexes served by others.
+func newHTTPIndexer(opt *ociIndexerOpts) (*httpIndexer, error) {
+	ref, err := url.Parse(opt.reference)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidArgument, "invalid index URL: %+v", err)
+	}
+	ind := &httpIndexer{url: opt.reference, client: utils.DefaultClient}
+	if opt.insecure {
+		ind.client = utils.InsecureClient
+	}
+	if host, err := url.Parse(opt.url); err == nil && host.Host == ref.Host {
+		ind.username, ind.password = opt.username, opt.password
+	}
+	return ind, nil
+}
+
+// Get implements Indexer
+func (ind *httpIndexer) Get(ctx context.Context) (*api.Index, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ind.url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create index request")
+	}
+	if ind.username != "" && ind.password != "" {
+		req.SetBasicAuth(ind.username, ind.password)
+	}
+	res, err := ind.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to download index")
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("%s: %s", ind.url, res.Status))
+	case res.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("unable to download index from %q: %s", ind.url, res.Status)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to download index")
+	}
+	return parseIndex(data)
+}
@@ -3,12 +3,59 @@ package indexer
 
 import (
 	"context"
+	"strings"
 
 	"github.com/bitnami/charts-syncer/internal/indexer/api"
 )
 
+// Schemes of the index references selecting the indexer implementation
+const (
+	fileScheme  = "file://"
+	httpScheme  = "http://"
+	httpsScheme = "https://"
+	ociScheme   = "oci://"
+)
+
+// Reference returns the reference of an OCI charts index without the optional
+// oci:// scheme
+func Reference(ref string) string {
+	return strings.TrimPrefix(ref, ociScheme)
+}
+
 // Indexer is the interface that an indexer should implement
 type Indexer interface {
 	// Get retrieves the index
 	Get(ctx context.Context) (*api.Index, error)
 }
+
+// Publisher is the interface that an indexer able to publish the index should
+// implement
+type Publisher interface {
+	Indexer
+	// Push publishes the index, replacing the existing one
+	Push(ctx context.Context, idx *api.Index) error
+}
+
+// New returns the indexer of the index reference configured with
+// WithIndexRef, selected by its scheme:
+//
+//   - file://path/to/index.yaml reads a local file
+//   - http:// and https:// URLs download the index from a web server
+//   - oci:// references, or references without a scheme, pull an OCI artifact
+//
+// Indexes can be charts indexes or Helm index.yaml files.
+func New(opts ...OciIndexerOpt) (Indexer, error) {
+	opt := &ociIndexerOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	switch ref := opt.reference; {
+	case strings.HasPrefix(ref, fileScheme):
+		return newFileIndexer(ref), nil
+	case strings.HasPrefix(ref, httpScheme), strings.HasPrefix(ref, httpsScheme):
+		return newHTTPIndexer(opt)
+	default:
+		return newOciIndexer(opts...)
+	}
+}
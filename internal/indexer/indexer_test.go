@@ -0,0 +1,117 @@
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const chartsIndex = `{
+  "apiVersion": "v1",
+  "entries": {
+    "apache": {
+      "versions": [
+        {
+          "version": "8.10.1",
+          "name": "apache",
+          "digest": "sha256:34d7e5e1fd652066aa1583a89f3823d355696f2df2953fca987df49fffa962d5",
+          "releasedAt": "2022-01-12T12:49:54.381313Z"
+        }
+      ]
+    }
+  }
+}`
+
+const helmIndex = `apiVersion: v1
+entries:
+  apache:
+  - name: apache
+    version: 8.10.1
+    appVersion: 2.4.51
+    created: "2022-01-12T12:49:54.381313Z"
+    deprecated: true
+    annotations:
+      category: Infrastructure
+    digest: 34d7e5e1fd652066aa1583a89f3823d355696f2df2953fca987df49fffa962d5
+    urls:
+    - https://charts.example.com/apache-8.10.1.tgz
+`
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"charts-index.json": chartsIndex, "index.yaml": helmIndex} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.FileServer(http.Dir(dir)).ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	tests := []struct {
+		desc           string
+		ref            string
+		wantDeprecated bool
+		wantNotFound   bool
+	}{
+		{desc: "charts index file", ref: "file://" + filepath.Join(dir, "charts-index.json")},
+		{desc: "helm index file", ref: "file://" + filepath.Join(dir, "index.yaml"), wantDeprecated: true},
+		{desc: "missing file", ref: "file://" + filepath.Join(dir, "missing.yaml"), wantNotFound: true},
+		{desc: "charts index url", ref: s.URL + "/charts-index.json"},
+		{desc: "helm index url", ref: s.URL + "/index.yaml", wantDeprecated: true},
+		{desc: "missing url", ref: s.URL + "/missing.yaml", wantNotFound: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			// The credentials of the repository are not sent to other hosts
+			ind, err := New(WithIndexRef(tc.ref), WithHost("https://registry.example.com"), WithBasicAuth("user", "password"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			idx, err := ind.Get(context.Background())
+			if tc.wantNotFound {
+				if !IsNotFound(err) {
+					t.Errorf("got %v error, want a not found one", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			versions := idx.GetEntries()["apache"].GetVersions()
+			if len(versions) != 1 {
+				t.Fatalf("got %d apache versions, want 1", len(versions))
+			}
+			v := versions[0]
+			if v.GetVersion() != "8.10.1" || v.GetDeprecated() != tc.wantDeprecated {
+				t.Errorf("got %s version, deprecated %v, want 8.10.1, deprecated %v", v.GetVersion(), v.GetDeprecated(), tc.wantDeprecated)
+			}
+			if want := "sha256:34d7e5e1fd652066aa1583a89f3823d355696f2df2953fca987df49fffa962d5"; v.GetDigest() != want {
+				t.Errorf("got %q digest, want %q", v.GetDigest(), want)
+			}
+			if v.GetReleasedAt() == "" {
+				t.Errorf("got an empty release date")
+			}
+		})
+	}
+}
+
+func TestNewOciPublisher(t *testing.T) {
+	for _, ref := range []string{"registry.example.com/charts/charts-index:latest", "oci://registry.example.com/charts/charts-index:latest"} {
+		pub, err := NewOciPublisher(WithIndexRef(ref), WithHost("https://registry.example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := pub.(*ociIndexer).reference, "registry.example.com/charts/charts-index:latest"; got != want {
+			t.Errorf("got %q reference, want %q", got, want)
+		}
+	}
+}
@@ -29,7 +29,8 @@ type ociIndexerOpts struct {
 type OciIndexerOpt func(opts *ociIndexerOpts)
 
 // WithIndexRef configures the charts index OCI reference instead of letting the library
-// using the default host/index:latest one.
+// using the default host/index:latest one. Indexers created with New also
+// accept file:// and http(s):// references.
 //
 //	opt := WithIndexRef("my.oci.domain/index:prod")
 func WithIndexRef(r string) OciIndexerOpt {
@@ -74,6 +75,16 @@ type ociIndexer struct {
 
 // NewOciIndexer returns a new OCI-based indexer
 func NewOciIndexer(opts ...OciIndexerOpt) (Indexer, error) {
+	return newOciIndexer(opts...)
+}
+
+// NewOciPublisher returns a new OCI-based indexer able to publish the index.
+// The index reference may have the oci:// scheme.
+func NewOciPublisher(opts ...OciIndexerOpt) (Publisher, error) {
+	return newOciIndexer(opts...)
+}
+
+func newOciIndexer(opts ...OciIndexerOpt) (*ociIndexer, error) {
 	opt := &ociIndexerOpts{}
 	for _, o := range opts {
 		o(opt)
@@ -86,7 +97,7 @@ func NewOciIndexer(opts ...OciIndexerOpt) (Indexer, error) {
 	resolver := newDockerResolver(u, opt.username, opt.password, opt.insecure)
 
 	ind := &ociIndexer{
-		reference: opt.reference,
+		reference: Reference(opt.reference),
 		resolver:  resolver,
 	}
 
@@ -128,12 +139,7 @@ func (ind *ociIndexer) Get(ctx context.Context) (idx *api.Index, e error) {
 	}
 
 	// Populate and return index
-	idx = &api.Index{}
-	u := protojson.UnmarshalOptions{DiscardUnknown: true}
-	if err := u.Unmarshal(data, idx); err != nil {
-		return nil, errors.Wrapf(err, "unable to parse index file")
-	}
-	return idx, nil
+	return parseIndex(data)
 }
 
 func (ind *ociIndexer) downloadIndex(ctx context.Context, rootPath string) (f string, e error) {
@@ -185,3 +191,40 @@ func (ind *ociIndexer) downloadIndex(ctx context.Context, rootPath string) (f st
 
 	return store.ResolvePath(indexFilename), nil
 }
+
+// Push implements Publisher
+func (ind *ociIndexer) Push(ctx context.Context, idx *api.Index) error {
+	data, err := protojson.Marshal(idx)
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal index")
+	}
+
+	store := content.NewMemory()
+	ctx = remotes.WithMediaTypeKeyPrefix(ctx, chartsIndexLayerMediaType, "layer-")
+	ctx = remotes.WithMediaTypeKeyPrefix(ctx, chartsIndexConfigMediaType, "config-")
+
+	layerDesc, err := store.Add(defaultIndexFilename, chartsIndexLayerMediaType, data)
+	if err != nil {
+		return errors.Wrapf(err, "unable to add index layer")
+	}
+	configDesc, err := store.Add("", chartsIndexConfigMediaType, []byte("{}"))
+	if err != nil {
+		return errors.Wrapf(err, "unable to add index config")
+	}
+	manifest, manifestDesc, err := content.GenerateManifest(&configDesc, nil, layerDesc)
+	if err != nil {
+		return errors.Wrapf(err, "unable to generate index manifest")
+	}
+	if err := store.StoreManifest(ind.reference, manifestDesc, manifest); err != nil {
+		return errors.Wrapf(err, "unable to store index manifest")
+	}
+
+	opts := []oras.CopyOpt{
+		oras.WithAllowedMediaType(chartsIndexLayerMediaType, chartsIndexConfigMediaType),
+		oras.WithNameValidation(nil),
+	}
+	if _, err := oras.Copy(ctx, store, ind.reference, ind.resolver, ind.reference, opts...); err != nil {
+		return errors.Wrapf(err, "unable to push index to %q", ind.reference)
+	}
+	return nil
+}
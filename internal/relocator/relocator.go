@@ -0,0 +1,197 @@
+// Package relocator implements the relocation of chart container images using
+// image mapping rules
+package relocator
+
+import (
+	"bytes"
+	goerrors "errors"
+	"fmt"
+	"os"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/juju/errors"
+	cu "github.com/vmware-labs/distribution-tooling-for-helm/pkg/chartutils"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	dtutils "github.com/vmware-labs/distribution-tooling-for-helm/pkg/utils"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/klog"
+)
+
+// dockerHub is the name used in the mapping rules for the default registry
+const dockerHub = "docker.io"
+
+// Relocator rewrites the container images of a chart using a list of image
+// mapping rules. Images not matching any rule are relocated under a default
+// prefix, keeping the last part of their repository.
+type Relocator struct {
+	prefix string
+	rules  []*api.ImageMapping
+}
+
+// New creates a Relocator using the provided default prefix and rules
+func New(prefix string, rules ...*api.ImageMapping) *Relocator {
+	return &Relocator{prefix: prefix, rules: rules}
+}
+
+// Image returns the relocated reference of the provided image, keeping its
+// tag or digest.
+func (r *Relocator) Image(image string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", errors.Annotatef(err, "parsing %q image", image)
+	}
+
+	registry := ref.Context().RegistryStr()
+	if registry == name.DefaultRegistry {
+		registry = dockerHub
+	}
+	repository := fmt.Sprintf("%s/%s", registry, ref.Context().RepositoryStr())
+	for _, m := range r.rules {
+		to, ok := m.Map(repository)
+		if !ok {
+			continue
+		}
+		klog.V(4).Infof("Relocating %q image to %q", repository, to)
+		if _, ok := ref.(name.Digest); ok {
+			return fmt.Sprintf("%s@%s", to, ref.Identifier()), nil
+		}
+		return fmt.Sprintf("%s:%s", to, ref.Identifier()), nil
+	}
+
+	relocated, err := dtutils.RelocateImageURL(image, r.prefix, true)
+	return relocated, errors.Trace(err)
+}
+
+// RelocateChartDir relocates the images of the chart in chartDir and its
+// dependencies, including the nested ones, updating the values.yaml files, the
+// Chart.yaml annotations and the Images.lock file.
+func (r *Relocator) RelocateChartDir(chartDir string, annotationsKey string) error {
+	c, err := cu.LoadChart(chartDir, cu.WithAnnotationsKey(annotationsKey))
+	if err != nil {
+		return errors.Annotatef(err, "loading %q chart", chartDir)
+	}
+
+	errs := r.relocateChart(c, annotationsKey)
+	return goerrors.Join(errs, r.relocateDependencies(c, annotationsKey))
+}
+
+// relocateDependencies relocates the images of the subcharts of c, walking
+// the whole dependency tree
+func (r *Relocator) relocateDependencies(c *cu.Chart, annotationsKey string) error {
+	var errs error
+	for _, dep := range c.Dependencies() {
+		if err := goerrors.Join(r.relocateChart(dep, annotationsKey), r.relocateDependencies(dep, annotationsKey)); err != nil {
+			errs = goerrors.Join(errs, errors.Annotatef(err, "relocating %q subchart", dep.Name()))
+		}
+	}
+	return errs
+}
+
+func (r *Relocator) relocateChart(c *cu.Chart, annotationsKey string) error {
+	var errs error
+	if err := r.relocateValues(c); err != nil {
+		errs = goerrors.Join(errs, errors.Annotatef(err, "relocating values.yaml"))
+	}
+	if err := r.relocateAnnotations(c, annotationsKey); err != nil {
+		errs = goerrors.Join(errs, errors.Annotatef(err, "relocating Chart.yaml annotations"))
+	}
+	if err := r.relocateLockFile(c.LockFilePath()); err != nil {
+		errs = goerrors.Join(errs, errors.Annotatef(err, "relocating Images.lock"))
+	}
+	return errs
+}
+
+func (r *Relocator) relocateValues(c *cu.Chart) error {
+	valuesFile := c.ValuesFile()
+	if valuesFile == nil {
+		return nil
+	}
+	values, err := chartutil.ReadValues(valuesFile.Data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	elems, err := cu.FindImageElementsInValuesMap(values)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+
+	replacements := make(map[string]string)
+	for _, e := range elems {
+		relocated, err := r.Image(e.URL())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ref, err := name.ParseReference(relocated)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.Registry = ref.Context().RegistryStr()
+		e.Repository = ref.Context().RepositoryStr()
+		for k, v := range e.YamlReplaceMap() {
+			replacements[k] = v
+		}
+	}
+	data, err := dtutils.YamlSet(valuesFile.Data, replacements)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(os.WriteFile(c.AbsFilePath("values.yaml"), data, 0644))
+}
+
+func (r *Relocator) relocateAnnotations(c *cu.Chart, annotationsKey string) error {
+	images, err := c.GetAnnotatedImages()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	if err := r.relocateImages(images); err != nil {
+		return errors.Trace(err)
+	}
+	data, err := images.ToAnnotation()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(dtutils.YamlFileSet(c.AbsFilePath("Chart.yaml"), map[string]string{
+		fmt.Sprintf("$.annotations['%s']", annotationsKey): string(data),
+	}))
+}
+
+func (r *Relocator) relocateLockFile(lockFile string) error {
+	if !dtutils.FileExists(lockFile) {
+		return nil
+	}
+	lock, err := imagelock.FromYAMLFile(lockFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(lock.Images) == 0 {
+		return nil
+	}
+	if err := r.relocateImages(lock.Images); err != nil {
+		return errors.Trace(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := lock.ToYAML(buf); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(dtutils.SafeWriteFile(lockFile, buf.Bytes(), 0600))
+}
+
+func (r *Relocator) relocateImages(images imagelock.ImageList) error {
+	var errs error
+	for _, img := range images {
+		relocated, err := r.Image(img.Image)
+		if err != nil {
+			errs = goerrors.Join(errs, err)
+			continue
+		}
+		img.Image = relocated
+	}
+	return errs
+}
@@ -0,0 +1,92 @@
+package relocator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	dtutils "github.com/vmware-labs/distribution-tooling-for-helm/pkg/utils"
+)
+
+var rules = []*api.ImageMapping{
+	{From: "docker.io/bitnami/kafka", To: "registry.internal/streaming/kafka"},
+	{From: "docker.io/bitnami/*", To: "registry.internal/mirror/bitnami/*"},
+	{From: "quay.io/*", To: "registry.internal/quay/*"},
+}
+
+func TestImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "docker.io/bitnami/kafka:2.5.0", want: "registry.internal/streaming/kafka:2.5.0"},
+		{image: "bitnami/zookeeper:3.6.1", want: "registry.internal/mirror/bitnami/zookeeper:3.6.1"},
+		{
+			image: "quay.io/prometheus/node-exporter@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			want:  "registry.internal/quay/prometheus/node-exporter@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{image: "gcr.io/google-containers/pause:3.2", want: "registry.internal/default/google-containers/pause:3.2"},
+	}
+
+	r := New("registry.internal/default", rules...)
+	for _, tc := range tests {
+		got, err := r.Image(tc.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("relocating %q: got %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
+
+func TestRelocateChartDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := dtutils.Untar("../../testdata/kafka-10.3.3.wrap.tgz", dir, dtutils.TarConfig{StripComponents: 1}); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(dir, "chart")
+
+	// Subcharts of subcharts are relocated too
+	nestedDir := filepath.Join(chartDir, "charts", "zookeeper", "charts", "metrics")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, data := range map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: metrics\nversion: 1.0.0\n",
+		"values.yaml": "image:\n  registry: docker.io\n  repository: bitnami/redis-exporter\n  tag: 1.0.0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(nestedDir, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := New("registry.internal/default", rules...).RelocateChartDir(chartDir, "images"); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string][]string{
+		"values.yaml": {
+			"registry: registry.internal\n  repository: streaming/kafka",
+			"registry: registry.internal\n    repository: mirror/bitnami/minideb",
+		},
+		"charts/zookeeper/values.yaml": {
+			"registry: registry.internal\n  repository: mirror/bitnami/zookeeper",
+		},
+		"charts/zookeeper/charts/metrics/values.yaml": {
+			"registry: registry.internal\n  repository: mirror/bitnami/redis-exporter",
+		},
+	} {
+		data, err := os.ReadFile(filepath.Join(chartDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range want {
+			if !strings.Contains(string(data), w) {
+				t.Errorf("%s does not contain %q", file, w)
+			}
+		}
+	}
+}
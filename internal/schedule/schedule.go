@@ -0,0 +1,206 @@
+// Package schedule implements time windows starting at the times of cron
+// expressions
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Time zones are loaded from the binary if the system lacks them
+	_ "time/tzdata"
+)
+
+// searchLimit is how far windows are searched for
+const searchLimit = 366 * 24 * time.Hour
+
+// macros are the supported cron expression shortcuts
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// Whether the day of month and day of week fields are "*". If both are
+	// restricted, a day matching any of them matches
+	domAny, dowAny bool
+}
+
+// Parse parses a cron expression with minute, hour, day of month, month and
+// day of week fields, like "30 22 * * MON-FRI". Fields support "*", values,
+// ranges, steps and lists, and months and days of week can be named. Macros
+// like "@daily" are supported too.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[expr]; ok {
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q should have 5 fields: minute, hour, day of month, month and day of week", expr)
+	}
+
+	s := &Schedule{domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %v", expr, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %v", expr, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %v", expr, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %v", expr, err)
+	}
+	// Sunday is both 0 and 7
+	if s.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %v", expr, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseField returns the bitset of the values of a cron field between min and
+// max. Names, if any, are the values from min on.
+func parseField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		start, end := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if start, err = parseValue(from, min, max, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(to, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "N/S" starts at N and goes on until max
+				end = max
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		}
+		inc := 1
+		if hasStep {
+			var err error
+			if inc, err = strconv.Atoi(step); err != nil || inc <= 0 {
+				return 0, fmt.Errorf("invalid step %q", step)
+			}
+		}
+		for v := start; v <= end; v += inc {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a value of a cron field, or its name
+func parseValue(s string, min, max int, names []string) (int, error) {
+	for i, n := range names {
+		if strings.EqualFold(s, n) {
+			return min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("%q should be between %d and %d", s, min, max)
+	}
+	return v, nil
+}
+
+// Matches returns whether the minute of t matches the schedule
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<t.Minute()) == 0 || s.hour&(1<<t.Hour()) == 0 || s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Window is a time window opening at the times of a schedule, for a duration
+type Window struct {
+	Schedule *Schedule
+	Duration time.Duration
+	// Location is the time zone of the schedule
+	Location *time.Location
+}
+
+// NewWindow returns a window opening at the times of the cron expression, for
+// the duration, like "2h". The expression is evaluated in the time zone, like
+// "Europe/Madrid", or in UTC if empty.
+func NewWindow(expr, duration, timeZone string) (*Window, error) {
+	s, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q: %v", duration, err)
+	}
+	if d < time.Minute {
+		return nil, fmt.Errorf("duration %q should be at least 1m", duration)
+	}
+	loc := time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %v", timeZone, err)
+		}
+	}
+	return &Window{Schedule: s, Duration: d, Location: loc}, nil
+}
+
+// Contains returns whether t is inside the window, that is, whether it opened
+// less than its duration before t
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.Location)
+	for start := t.Truncate(time.Minute); t.Sub(start) < w.Duration && t.Sub(start) < searchLimit; start = start.Add(-time.Minute) {
+		if w.Schedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the next time after t the window opens, and false if it does
+// not open within a year
+func (w *Window) Next(t time.Time) (time.Time, bool) {
+	t = t.In(w.Location)
+	for start := t.Truncate(time.Minute).Add(time.Minute); start.Sub(t) < searchLimit; start = start.Add(time.Minute) {
+		if w.Schedule.Matches(start) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
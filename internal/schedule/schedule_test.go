@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		matches []string
+		misses  []string
+		wantErr bool
+	}{
+		{
+			expr:    "30 22 * * MON-FRI",
+			matches: []string{"2026-10-19T22:30:00Z", "2026-10-23T22:30:00Z"},
+			misses:  []string{"2026-10-19T22:31:00Z", "2026-10-24T22:30:00Z"},
+		},
+		{
+			expr:    "*/15 9-17 * * *",
+			matches: []string{"2026-10-19T09:00:00Z", "2026-10-19T17:45:00Z"},
+			misses:  []string{"2026-10-19T09:10:00Z", "2026-10-19T18:00:00Z"},
+		},
+		{
+			// Days of month and of week match any of them if both are restricted
+			expr:    "0 0 1 * sun",
+			matches: []string{"2026-10-01T00:00:00Z", "2026-10-18T00:00:00Z"},
+			misses:  []string{"2026-10-19T00:00:00Z"},
+		},
+		{
+			expr:    "0 0 * JAN,JUL 7",
+			matches: []string{"2026-01-04T00:00:00Z", "2026-07-05T00:00:00Z"},
+			misses:  []string{"2026-10-18T00:00:00Z", "2026-07-06T00:00:00Z"},
+		},
+		{
+			expr:    "5/20 * * * *",
+			matches: []string{"2026-10-19T10:05:00Z", "2026-10-19T10:45:00Z"},
+			misses:  []string{"2026-10-19T10:00:00Z", "2026-10-19T10:20:00Z"},
+		},
+		{
+			expr:    "@daily",
+			matches: []string{"2026-10-19T00:00:00Z"},
+			misses:  []string{"2026-10-19T01:00:00Z"},
+		},
+		{expr: "* * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "0 10-8 * * *", wantErr: true},
+		{expr: "0 * * * FUNDAY", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			for _, m := range tc.matches {
+				if !s.Matches(mustParseTime(t, m)) {
+					t.Errorf("want %q to match %s", tc.expr, m)
+				}
+			}
+			for _, m := range tc.misses {
+				if s.Matches(mustParseTime(t, m)) {
+					t.Errorf("want %q not to match %s", tc.expr, m)
+				}
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w, err := NewWindow("0 22 * * MON-FRI", "2h", "Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 22:00 in Madrid is 20:00 UTC in October, before the DST change
+	for _, c := range []struct {
+		time     string
+		contains bool
+	}{
+		{time: "2026-10-19T19:59:00Z", contains: false},
+		{time: "2026-10-19T20:00:00Z", contains: true},
+		{time: "2026-10-19T21:59:59Z", contains: true},
+		{time: "2026-10-19T22:00:00Z", contains: false},
+		{time: "2026-10-23T21:30:00Z", contains: true},
+		// There are no windows on weekends
+		{time: "2026-10-24T20:30:00Z", contains: false},
+	} {
+		if got := w.Contains(mustParseTime(t, c.time)); got != c.contains {
+			t.Errorf("Contains(%s) = %v, want %v", c.time, got, c.contains)
+		}
+	}
+
+	next, ok := w.Next(mustParseTime(t, "2026-10-24T10:00:00Z"))
+	if !ok {
+		t.Fatal("want the window to open again")
+	}
+	if want := mustParseTime(t, "2026-10-26T21:00:00Z"); !next.Equal(want) {
+		t.Errorf("got next window at %s, want %s", next, want)
+	}
+
+	if _, err := NewWindow("0 22 * * *", "30s", ""); err == nil {
+		t.Error("want an error for a window shorter than a minute")
+	}
+	if _, err := NewWindow("0 22 * * *", "1h", "Mars/Olympus_Mons"); err == nil {
+		t.Error("want an error for an unknown time zone")
+	}
+	never, err := NewWindow("0 0 30 2 *", "1h", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := never.Next(mustParseTime(t, "2026-10-24T10:00:00Z")); ok {
+		t.Error("want a window on February 30th never to open")
+	}
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	tm, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
@@ -0,0 +1,206 @@
+// Package sign implements cosign compatible signing of OCI artifacts
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/juju/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/bitnami/charts-syncer/internal/verify"
+)
+
+// PEM block types of the private keys generated by cosign
+var encryptedKeyTypes = map[string]bool{
+	"ENCRYPTED SIGSTORE PRIVATE KEY": true,
+	"ENCRYPTED COSIGN PRIVATE KEY":   true,
+}
+
+// Signer signs OCI artifacts the same way "cosign sign --key" does, without
+// uploading the signatures to a transparency log
+type Signer struct {
+	key crypto.Signer
+}
+
+// encryptedKey is the JSON content of the encrypted private keys generated by
+// cosign
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadPrivateKey loads the private key in path. Both encrypted cosign keys,
+// decrypted with password, and unencrypted PEM encoded keys are supported.
+func LoadPrivateKey(path, password string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("%q is not a PEM encoded private key", path)
+	}
+
+	der := block.Bytes
+	if encryptedKeyTypes[block.Type] {
+		if der, err = decrypt(block.Bytes, password); err != nil {
+			return nil, errors.Annotatef(err, "decrypting %q private key", path)
+		}
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "parsing %q private key", path)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return &Signer{key: k.(crypto.Signer)}, nil
+	}
+	return nil, errors.Errorf("%q private key type %T is not supported", path, key)
+}
+
+// decrypt decrypts the content of an encrypted cosign private key
+func decrypt(data []byte, password string) ([]byte, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if k.KDF.Name != "scrypt" || k.Cipher.Name != "nacl/secretbox" {
+		return nil, errors.Errorf("unsupported %q key derivation function and %q cipher", k.KDF.Name, k.Cipher.Name)
+	}
+	if len(k.Cipher.Nonce) != 24 {
+		return nil, errors.Errorf("invalid nonce length %d", len(k.Cipher.Nonce))
+	}
+	secret, err := scrypt.Key([]byte(password), k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var nonce [24]byte
+	var key [32]byte
+	copy(nonce[:], k.Cipher.Nonce)
+	copy(key[:], secret)
+	der, ok := secretbox.Open(nil, k.Ciphertext, &nonce, &key)
+	if !ok {
+		return nil, errors.New("wrong password")
+	}
+	return der, nil
+}
+
+// payload returns the simple signing payload of a manifest
+func payload(repository, digest string) ([]byte, error) {
+	p := map[string]interface{}{
+		"critical": map[string]interface{}{
+			"identity": map[string]string{"docker-reference": repository},
+			"image":    map[string]string{"docker-manifest-digest": digest},
+			"type":     "cosign container image signature",
+		},
+		"optional": nil,
+	}
+	return json.Marshal(p)
+}
+
+// signPayload signs a payload with the key
+func (s *Signer) signPayload(p []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, p, crypto.Hash(0))
+	}
+	h := sha256.Sum256(p)
+	return s.key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+// Sign appends the signature of the manifest with the provided digest, stored
+// in repository, to the cosign signature image sig
+func (s *Signer) Sign(sig v1.Image, repository, digest string) (v1.Image, error) {
+	p, err := payload(repository, digest)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	signature, err := s.signPayload(p)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	img, err := mutate.Append(sig, mutate.Addendum{
+		Layer:       static.NewLayer(p, ggcrtypes.MediaType(verify.SimpleSigningMediaType)),
+		Annotations: map[string]string{verify.SignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return img, nil
+}
+
+// SignRemote signs the manifest referenced by ref, and pushes the signature
+// with the cosign tag schema, i.e "sha256-<hex>.sig". Signatures already
+// attached to the manifest are kept. The signature tag is returned.
+func (s *Signer) SignRemote(ref name.Reference, opts ...remote.Option) (name.Tag, error) {
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return name.Tag{}, errors.Annotatef(err, "failed checking remote: %s", ref)
+	}
+	tag := ref.Context().Tag(fmt.Sprintf("%s-%s.sig", desc.Digest.Algorithm, desc.Digest.Hex))
+
+	sig, err := remote.Image(tag, opts...)
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		sig, err = emptySignature(), nil
+	}
+	if err != nil {
+		return name.Tag{}, errors.Annotatef(err, "fetching %q", tag)
+	}
+
+	signed, err := s.Sign(sig, ref.Context().Name(), desc.Digest.String())
+	if err != nil {
+		return name.Tag{}, errors.Annotatef(err, "signing %q", ref)
+	}
+	if err := remote.Write(tag, signed, opts...); err != nil {
+		return name.Tag{}, errors.Annotatef(err, "pushing %q", tag)
+	}
+	return tag, nil
+}
+
+// emptySignature returns a cosign signature image without signatures
+func emptySignature() v1.Image {
+	img := mutate.MediaType(empty.Image, ggcrtypes.OCIManifestSchema1)
+	return mutate.ConfigMediaType(img, ggcrtypes.OCIConfigJSON)
+}
@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/bitnami/charts-syncer/internal/verify"
+)
+
+const digest = "sha256:4a5f9c2f3d8e1b0a6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b"
+
+// writeKeys writes a new private key, encrypted with password if it is not
+// empty, and its public key
+func writeKeys(t *testing.T, password string) (string, string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	if password != "" {
+		block = &pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: encrypt(t, der, password)}
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	privFile, pubFile := filepath.Join(dir, "cosign.key"), filepath.Join(dir, "cosign.pub")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privFile, pubFile
+}
+
+// encrypt encrypts a private key the same way cosign does
+func encrypt(t *testing.T, der []byte, password string) []byte {
+	t.Helper()
+	var k encryptedKey
+	k.KDF.Name, k.Cipher.Name = "scrypt", "nacl/secretbox"
+	k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P = 32768, 8, 1
+	k.KDF.Salt = make([]byte, 32)
+	k.Cipher.Nonce = make([]byte, 24)
+	if _, err := rand.Read(k.KDF.Salt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rand.Read(k.Cipher.Nonce); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := scrypt.Key([]byte(password), k.KDF.Salt, k.KDF.Params.N, k.KDF.Params.R, k.KDF.Params.P, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nonce [24]byte
+	var key [32]byte
+	copy(nonce[:], k.Cipher.Nonce)
+	copy(key[:], secret)
+	k.Ciphertext = secretbox.Seal(nil, der, &nonce, &key)
+	data, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		desc     string
+		password string
+	}{
+		{desc: "unencrypted key"},
+		{desc: "encrypted cosign key", password: "s3cr3t"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			privFile, pubFile := writeKeys(t, tc.password)
+			s, err := LoadPrivateKey(privFile, tc.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub, err := verify.LoadPublicKey(pubFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sig, err := s.Sign(emptySignature(), "example.com/charts/apache", digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pub.Verify(digest, []v1.Image{sig}); err != nil {
+				t.Errorf("unexpected verification error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPrivateKeyWrongPassword(t *testing.T) {
+	privFile, _ := writeKeys(t, "s3cr3t")
+	if _, err := LoadPrivateKey(privFile, "wrong"); err == nil {
+		t.Errorf("expected error decrypting the key with a wrong password")
+	}
+}
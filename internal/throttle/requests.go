@@ -0,0 +1,229 @@
+package throttle
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog"
+)
+
+const (
+	// DefaultMaxRetryWait is the default maximum time to wait before retrying
+	// a request rejected with a 429 status
+	DefaultMaxRetryWait = time.Minute
+	// maxRetries is the maximum number of times a request rejected with a 429
+	// status is retried
+	maxRetries = 5
+)
+
+var (
+	requestsMu sync.Mutex
+	// requestsPerSecond is the maximum rate of requests sent to each host
+	requestsPerSecond rate.Limit
+	// registryRequests are the maximum rates of requests sent to specific
+	// registries, indexed by registry
+	registryRequests map[string]rate.Limit
+	// requestLimiters are the limiters pacing the requests, indexed by the
+	// registry or host they apply to
+	requestLimiters map[string]*rate.Limiter
+	maxRetryWait    = DefaultMaxRetryWait
+
+	quotasMu sync.Mutex
+	// quotas are the last request quotas announced by the hosts, indexed by
+	// host
+	quotas map[string]*Quota
+)
+
+// Quota is the request quota announced by a host with the RateLimit-Limit and
+// RateLimit-Remaining headers
+type Quota struct {
+	Host      string `json:"host"`
+	Limit     int    `json:"limit,omitempty"`
+	Remaining int    `json:"remaining"`
+}
+
+func (q *Quota) String() string {
+	if q.Limit > 0 {
+		return fmt.Sprintf("%d of %d requests remaining in the %q quota", q.Remaining, q.Limit, q.Host)
+	}
+	return fmt.Sprintf("%d requests remaining in the %q quota", q.Remaining, q.Host)
+}
+
+// ConfigureRequests limits the requests per second sent to each host, and to
+// specific registries, matched like in Configure. A limit of zero disables it.
+// Requests rejected with a 429 status are retried if the host asks to wait
+// less than maxWait.
+func ConfigureRequests(perHost float64, perRegistry map[string]float64, maxWait time.Duration) {
+	requestsMu.Lock()
+	defer requestsMu.Unlock()
+
+	requestsPerSecond = rate.Limit(perHost)
+	registryRequests = map[string]rate.Limit{}
+	for r, l := range perRegistry {
+		if l > 0 {
+			registryRequests[r] = rate.Limit(l)
+		}
+	}
+	requestLimiters = map[string]*rate.Limiter{}
+	maxRetryWait = maxWait
+	if requestsPerSecond > 0 || len(registryRequests) > 0 {
+		install()
+	}
+}
+
+// Proxy waits until the request rate limits allow sending the request, and
+// returns the proxy to send it through from the environment. It is meant to be
+// used as the Proxy of HTTP transports, which call it for every request.
+func Proxy(req *http.Request) (*url.URL, error) {
+	if l := requestLimiter(req.URL.Host); l != nil {
+		if err := l.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// requestLimiter returns the limiter pacing the requests to a host, or nil if
+// they are not limited. Registries share a limiter with their subdomains.
+func requestLimiter(host string) *rate.Limiter {
+	requestsMu.Lock()
+	defer requestsMu.Unlock()
+
+	key, limit := host, requestsPerSecond
+	for r, l := range registryRequests {
+		if matches(r, host) {
+			key, limit = r, l
+			break
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+	l, ok := requestLimiters[key]
+	if !ok {
+		l = rate.NewLimiter(limit, 1)
+		requestLimiters[key] = l
+	}
+	return l
+}
+
+// Transport returns a round tripper sending the requests with base, and
+// retrying the ones rejected with a 429 Too Many Requests status after the time
+// the Retry-After or RateLimit-Reset headers ask for. The request quotas
+// announced by the hosts are recorded.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		recordQuota(req.URL.Host, res.Header)
+		if res.StatusCode != http.StatusTooManyRequests || attempt == maxRetries {
+			return res, nil
+		}
+
+		requestsMu.Lock()
+		maxWait := maxRetryWait
+		requestsMu.Unlock()
+		wait := retryAfter(res.Header, attempt)
+		if wait > maxWait {
+			klog.Warningf("%q rate limited the requests for %v, longer than the maximum wait of %v", req.URL.Host, wait, maxWait)
+			return res, nil
+		}
+		// Requests with a body can only be retried if it can be read again
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return res, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return res, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		res.Body.Close()
+
+		klog.V(2).Infof("%q rate limited the requests, retrying in %v", req.URL.Host, wait)
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryAfter returns how long to wait before retrying a request rejected with
+// a 429 status. It backs off exponentially if the response does not tell.
+func retryAfter(h http.Header, attempt int) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if s, err := strconv.Atoi(v); err == nil {
+			return time.Duration(s) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0)
+		}
+	}
+	if s, ok := headerInt(h, "RateLimit-Reset"); ok {
+		return time.Duration(s) * time.Second
+	}
+	return time.Second << attempt
+}
+
+// recordQuota records the request quota announced by the headers of a
+// response from host, if any
+func recordQuota(host string, h http.Header) {
+	remaining, ok := headerInt(h, "RateLimit-Remaining")
+	if !ok {
+		return
+	}
+	limit, _ := headerInt(h, "RateLimit-Limit")
+
+	quotasMu.Lock()
+	defer quotasMu.Unlock()
+	if quotas == nil {
+		quotas = make(map[string]*Quota)
+	}
+	quotas[host] = &Quota{Host: host, Limit: limit, Remaining: remaining}
+}
+
+// headerInt returns the integer value of a header. Parameters like the window
+// of "100;w=21600" are ignored.
+func headerInt(h http.Header, key string) (int, bool) {
+	v, _, _ := strings.Cut(h.Get(key), ";")
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Quotas returns the last request quotas announced by the hosts, sorted by
+// host
+func Quotas() []*Quota {
+	quotasMu.Lock()
+	defer quotasMu.Unlock()
+
+	list := make([]*Quota, 0, len(quotas))
+	for _, q := range quotas {
+		c := *q
+		list = append(list, &c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Host < list[j].Host })
+	return list
+}
@@ -0,0 +1,145 @@
+package throttle
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if strings.HasSuffix(r.URL.Path, "/exhausted") {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if n <= 2 {
+			w.Header().Set("RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("RateLimit-Limit", "100;w=21600")
+		w.Header().Set("RateLimit-Remaining", "42;w=21600")
+	}))
+	t.Cleanup(s.Close)
+	t.Cleanup(func() { ConfigureRequests(0, nil, DefaultMaxRetryWait) })
+	ConfigureRequests(0, nil, time.Minute)
+
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	res, err := client.Get(s.URL + "/v2/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("got %d status, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quota *Quota
+	for _, q := range Quotas() {
+		if q.Host == u.Host {
+			quota = q
+		}
+	}
+	if quota == nil || quota.Limit != 100 || quota.Remaining != 42 {
+		t.Errorf("got %+v quota, want 42 of 100 requests remaining", quota)
+	}
+	if got, want := quota.String(), fmt.Sprintf("42 of 100 requests remaining in the %q quota", u.Host); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Requests asked to wait longer than the maximum are not retried
+	requests.Store(0)
+	res, err = client.Get(s.URL + "/v2/exhausted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got %d status, want %d", res.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		desc    string
+		headers map[string]string
+		attempt int
+		want    time.Duration
+	}{
+		{desc: "retry after seconds", headers: map[string]string{"Retry-After": "30"}, want: 30 * time.Second},
+		{desc: "ratelimit reset", headers: map[string]string{"RateLimit-Reset": "12"}, want: 12 * time.Second},
+		{desc: "retry after preferred", headers: map[string]string{"Retry-After": "5", "RateLimit-Reset": "12"}, want: 5 * time.Second},
+		{desc: "past retry after date", headers: map[string]string{"Retry-After": "Wed, 21 Oct 2015 07:28:00 GMT"}, want: 0},
+		{desc: "exponential backoff", attempt: 3, want: 8 * time.Second},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.headers {
+				h.Set(k, v)
+			}
+			if got := retryAfter(h, tc.attempt); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProxy(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(s.Close)
+	t.Cleanup(func() { ConfigureRequests(0, nil, DefaultMaxRetryWait) })
+
+	tests := []struct {
+		desc        string
+		perHost     float64
+		perRegistry map[string]float64
+		paced       bool
+	}{
+		{desc: "no limits"},
+		{desc: "host limit", perHost: 10, paced: true},
+		{desc: "registry limit", perRegistry: map[string]float64{"127.0.0.1": 10}, paced: true},
+		{desc: "other registry limit", perRegistry: map[string]float64{"docker.io": 10}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			ConfigureRequests(tc.perHost, tc.perRegistry, DefaultMaxRetryWait)
+			client := &http.Client{Transport: &http.Transport{Proxy: Proxy}}
+			start := time.Now()
+			// The first request is not delayed, so 6 requests at 10 per
+			// second take half a second
+			for i := 0; i < 6; i++ {
+				res, err := client.Get(s.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				res.Body.Close()
+			}
+			elapsed := time.Since(start)
+			if tc.paced && elapsed < 400*time.Millisecond {
+				t.Errorf("requests took %v, want them paced", elapsed)
+			}
+			if !tc.paced && elapsed > 300*time.Millisecond {
+				t.Errorf("requests took %v, want them not paced", elapsed)
+			}
+		})
+	}
+}
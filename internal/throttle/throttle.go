@@ -0,0 +1,249 @@
+// Package throttle limits the bandwidth and the rate of requests used to
+// transfer charts and container images
+package throttle
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ggcrtransport "github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/time/rate"
+	"k8s.io/klog"
+)
+
+// maxChunkSize is the maximum number of bytes read or written at once, so the
+// transfers are throttled smoothly
+const maxChunkSize = 32 * 1024
+
+var (
+	mu sync.RWMutex
+	// global limits all the transfers
+	global *rate.Limiter
+	// registries limits the transfers with specific hosts, indexed by host
+	registries map[string]*rate.Limiter
+
+	dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	// installed hooks the throttled dialer and the request rate limits into
+	// the go-containerregistry default transport
+	installed sync.Once
+	// retried wraps the go-containerregistry default transport to retry the
+	// requests rejected with a 429 status
+	retried sync.Once
+	// transfersRetried is whether the container image transfers are retried as
+	// a whole, as their requests can not be retried with --insecure
+	transfersRetried atomic.Bool
+	// registryTransport is the go-containerregistry default transport, before
+	// wrapping it
+	registryTransport, _ = remote.DefaultTransport.(*http.Transport)
+)
+
+// Configure limits the bandwidth, in bytes per second, of all the transfers,
+// and of the transfers with specific registries. A limit of zero disables it.
+//
+// Registries are hosts, optionally with a port, and they are matched against
+// the hosts dialed, including their subdomains: "docker.io" matches
+// "registry-1.docker.io". Downloads redirected to other hosts, like the CDNs
+// some registries serve blobs from, are only throttled by the global limit.
+func Configure(max int64, perRegistry map[string]int64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	global = newLimiter(max)
+	registries = map[string]*rate.Limiter{}
+	for r, m := range perRegistry {
+		if l := newLimiter(m); l != nil {
+			registries[r] = l
+		}
+	}
+	if global != nil || len(registries) > 0 {
+		install()
+	}
+}
+
+// install hooks the throttled dialer and the request rate limits into the
+// default transport of go-containerregistry, which transfers the container
+// images during wrap and unwrap. The transport must remain an *http.Transport,
+// as go-containerregistry clones it for insecure registries.
+func install() {
+	installed.Do(func() {
+		if registryTransport != nil {
+			registryTransport.DialContext = DialContext
+			registryTransport.Proxy = Proxy
+		}
+	})
+}
+
+// RetryImageTransfers wraps the default transport of go-containerregistry with
+// Transport, so the container image transfers during wrap and unwrap retry the
+// requests rejected with a 429 status too. go-containerregistry can only clone
+// its default transport for insecure registries if it is an *http.Transport,
+// so it is not wrapped if insecure is set. RetryTransfer retries the whole
+// transfers instead.
+func RetryImageTransfers(insecure bool) {
+	if registryTransport == nil {
+		return
+	}
+	if insecure {
+		transfersRetried.Store(true)
+		return
+	}
+	retried.Do(func() {
+		remote.DefaultTransport = Transport(registryTransport)
+	})
+}
+
+// RetryTransfer runs a container image transfer, like wrapping or unwrapping a
+// chart. If its requests can not be retried, the whole transfer is retried
+// when a registry rejects a request with a 429 status, backing off
+// exponentially up to the maximum retry wait.
+func RetryTransfer(fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !transfersRetried.Load() || !isTooManyRequests(err) || attempt == maxRetries {
+			return err
+		}
+
+		requestsMu.Lock()
+		maxWait := maxRetryWait
+		requestsMu.Unlock()
+		wait := retryAfter(nil, attempt)
+		if wait > maxWait {
+			return err
+		}
+		klog.V(2).Infof("A registry rate limited the container image transfer, retrying it in %v", wait)
+		time.Sleep(wait)
+	}
+}
+
+// isTooManyRequests returns whether an error is caused by a response with a
+// 429 status. Some errors only keep the message of the registry error.
+func isTooManyRequests(err error) bool {
+	var terr *ggcrtransport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "429 Too Many Requests") || strings.Contains(msg, string(ggcrtransport.TooManyRequestsErrorCode))
+}
+
+// RegistryTransport returns the transport to access container registries with
+// go-containerregistry. It is throttled with the configured limits, and it
+// retries the requests rejected with a 429 status. TLS certificates are not
+// verified if insecure is set.
+func RegistryTransport(insecure bool) http.RoundTripper {
+	if registryTransport == nil {
+		return Transport(remote.DefaultTransport)
+	}
+	var t http.RoundTripper = registryTransport
+	if insecure {
+		clone := registryTransport.Clone()
+		clone.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+		t = clone
+	}
+	return Transport(t)
+}
+
+// DialContext connects to the address like net.Dialer does, returning a
+// connection whose reads and writes are throttled with the configured limits.
+// It is meant to be used as the DialContext of HTTP transports.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	c, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	limiters := limitersFor(addr)
+	if len(limiters) == 0 {
+		return c, nil
+	}
+	chunkSize := maxChunkSize
+	for _, l := range limiters {
+		chunkSize = min(chunkSize, l.Burst())
+	}
+	return &conn{Conn: c, limiters: limiters, chunkSize: chunkSize}, nil
+}
+
+// newLimiter returns a limiter of max bytes per second, or nil if max is not
+// positive
+func newLimiter(max int64) *rate.Limiter {
+	if max <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(max), int(min(max, maxChunkSize)))
+}
+
+// limitersFor returns the limiters applying to the connections to addr
+func limitersFor(addr string) []*rate.Limiter {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var limiters []*rate.Limiter
+	if global != nil {
+		limiters = append(limiters, global)
+	}
+	for r, l := range registries {
+		if matches(r, addr) {
+			limiters = append(limiters, l)
+		}
+	}
+	return limiters
+}
+
+// matches returns whether a registry host, optionally with a port, matches
+// an address or any of its subdomains
+func matches(registry, addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return registry == addr || registry == host || strings.HasSuffix(host, "."+registry)
+}
+
+// conn is a connection throttled by a set of limiters
+type conn struct {
+	net.Conn
+	limiters  []*rate.Limiter
+	chunkSize int
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	if len(p) > c.chunkSize {
+		p = p[:c.chunkSize]
+	}
+	n, err := c.Conn.Read(p)
+	c.wait(n)
+	return n, err
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), c.chunkSize)]
+		c.wait(len(chunk))
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// wait blocks until all the limiters allow transferring n bytes
+func (c *conn) wait(n int) {
+	if n <= 0 {
+		return
+	}
+	for _, l := range c.limiters {
+		// WaitN only fails if n exceeds the burst, which chunkSize prevents
+		_ = l.WaitN(context.Background(), n)
+	}
+}
@@ -0,0 +1,163 @@
+package throttle
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ggcrtransport "github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+func TestLimitersFor(t *testing.T) {
+	Configure(0, map[string]int64{"docker.io": 1024, "localhost:5000": 1024})
+	t.Cleanup(func() { Configure(0, nil) })
+
+	tests := []struct {
+		addr string
+		want int
+	}{
+		{addr: "docker.io:443", want: 1},
+		{addr: "registry-1.docker.io:443", want: 1},
+		{addr: "notdocker.io:443", want: 0},
+		{addr: "localhost:5000", want: 1},
+		{addr: "localhost:5001", want: 0},
+	}
+	for _, tc := range tests {
+		if got := len(limitersFor(tc.addr)); got != tc.want {
+			t.Errorf("limitersFor(%q) returned %d limiters, want %d", tc.addr, got, tc.want)
+		}
+	}
+
+	Configure(1024, nil)
+	if got := len(limitersFor("docker.io:443")); got != 1 {
+		t.Errorf("got %d limiters with a global limit, want 1", got)
+	}
+}
+
+func TestDialContext(t *testing.T) {
+	const size = 256 * 1024
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(make([]byte, size))
+	}))
+	t.Cleanup(s.Close)
+	t.Cleanup(func() { Configure(0, nil) })
+
+	// download returns how long it takes to download the response body
+	download := func(t *testing.T) time.Duration {
+		t.Helper()
+		client := &http.Client{Transport: &http.Transport{DialContext: DialContext}}
+		start := time.Now()
+		res, err := client.Get(s.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		n, err := io.Copy(io.Discard, res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != size {
+			t.Fatalf("got %d bytes, want %d", n, size)
+		}
+		return time.Since(start)
+	}
+
+	tests := []struct {
+		desc        string
+		max         int64
+		perRegistry map[string]int64
+		throttled   bool
+	}{
+		{desc: "no limits"},
+		{desc: "global limit", max: size, throttled: true},
+		{desc: "registry limit", perRegistry: map[string]int64{"127.0.0.1": size}, throttled: true},
+		{desc: "other registry limit", perRegistry: map[string]int64{"docker.io": size}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			Configure(tc.max, tc.perRegistry)
+			// The first chunk is not delayed, so downloading the body at
+			// its size per second takes a bit less than a second
+			elapsed := download(t)
+			if tc.throttled && elapsed < 700*time.Millisecond {
+				t.Errorf("download took %v, want it throttled", elapsed)
+			}
+			if !tc.throttled && elapsed > 500*time.Millisecond {
+				t.Errorf("download took %v, want it not throttled", elapsed)
+			}
+		})
+	}
+}
+
+func TestRegistryTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	for _, insecure := range []bool{false, true} {
+		res, err := (&http.Client{Transport: RegistryTransport(insecure)}).Get(srv.URL)
+		if err == nil {
+			res.Body.Close()
+		}
+		if (err == nil) != insecure {
+			t.Errorf("got error %v with insecure %v", err, insecure)
+		}
+	}
+}
+
+func TestRetryTransfer(t *testing.T) {
+	tooManyRequests := &ggcrtransport.Error{StatusCode: http.StatusTooManyRequests}
+	transfer := func(failures int) (func() error, *int) {
+		calls := 0
+		return func() error {
+			calls++
+			if calls <= failures {
+				return fmt.Errorf("pushing images: %w", tooManyRequests)
+			}
+			return nil
+		}, &calls
+	}
+
+	// The requests are retried by the transport unless insecure is set
+	fn, calls := transfer(1)
+	if err := RetryTransfer(fn); err == nil || *calls != 1 {
+		t.Errorf("got error %v after %d calls, want an error after 1 call", err, *calls)
+	}
+
+	transfersRetried.Store(true)
+	defer transfersRetried.Store(false)
+	fn, calls = transfer(1)
+	if err := RetryTransfer(fn); err != nil || *calls != 2 {
+		t.Errorf("got error %v after %d calls, want no error after 2 calls", err, *calls)
+	}
+	// Other errors are not retried
+	calls = new(int)
+	err := RetryTransfer(func() error {
+		*calls++
+		return errors.New("unauthorized")
+	})
+	if err == nil || *calls != 1 {
+		t.Errorf("got error %v after %d calls, want an error after 1 call", err, *calls)
+	}
+}
+
+func TestIsTooManyRequests(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: &ggcrtransport.Error{StatusCode: http.StatusTooManyRequests}, want: true},
+		{err: errors.New("failed to push image: TOOMANYREQUESTS: retry later"), want: true},
+		{err: errors.New("unexpected status code 429 Too Many Requests"), want: true},
+		{err: &ggcrtransport.Error{StatusCode: http.StatusUnauthorized}},
+		{err: errors.New("connection refused")},
+	}
+	for _, tc := range tests {
+		if got := isTooManyRequests(tc.err); got != tc.want {
+			t.Errorf("got %v for %q, want %v", got, tc.err, tc.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"crypto/sha1" // #nosec G505 - we are not using it for security things
+	"crypto/sha256"
 	"crypto/tls"
 	goerrors "errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +25,7 @@ import (
 
 	"github.com/bitnami/charts-syncer/api"
 	"github.com/bitnami/charts-syncer/internal/cache"
+	"github.com/bitnami/charts-syncer/internal/throttle"
 )
 
 const (
@@ -35,12 +38,15 @@ var (
 	MaxDecompressionSize int64 = 8 * 1024 * 1024 * 1024
 	// UnixEpoch is the number of seconds that have elapsed since January 1, 1970
 	UnixEpoch = time.Unix(0, 0)
-	// DefaultClient is a default HTTP client
-	DefaultClient = &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}
-	// InsecureClient is a default insecure HTTPS client
-	InsecureClient = &http.Client{Transport: &http.Transport{
-		Proxy:           http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, // #nosec G402
+	// DefaultClient is a default HTTP client, throttled with the configured
+	// bandwidth and request rate limits
+	DefaultClient = &http.Client{Transport: throttle.Transport(&http.Transport{Proxy: throttle.Proxy, DialContext: throttle.DialContext})}
+	// InsecureClient is a default insecure HTTPS client, throttled with the
+	// configured bandwidth and request rate limits
+	InsecureClient = &http.Client{Transport: throttle.Transport(&http.Transport{
+		Proxy:           throttle.Proxy,
+		DialContext:     throttle.DialContext,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}), // #nosec G402
 	}
 )
 
@@ -224,16 +230,33 @@ func GetFileContentType(filepath string) (string, error) {
 	return contentType, err
 }
 
-// GetDateThreshold will parse a string date against a fixed layout and return a time.Date value
+// GetDateThreshold parses the date charts are synchronized from. It can be a
+// day, like "2020-05-15", an RFC3339 timestamp, like "2020-05-15T10:00:00Z", or
+// a duration relative to now, like "72h" or "14d".
 func GetDateThreshold(date string) (time.Time, error) {
+	return dateThreshold(date, time.Now())
+}
+
+func dateThreshold(date string, now time.Time) (time.Time, error) {
 	if date == "" {
 		return UnixEpoch, nil
 	}
-	dateThreshold, err := time.Parse(timeLayoutISO, date)
-	if err != nil {
-		return dateThreshold, errors.Trace(err)
+	if t, err := time.Parse(timeLayoutISO, date); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(date, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(date); err == nil && d >= 0 {
+		return now.Add(-d), nil
 	}
-	return dateThreshold, nil
+	return time.Time{}, errors.Errorf("invalid date %q, it should be a day like %q, an RFC3339 timestamp like %q or a duration like %q or %q",
+		date, timeLayoutISO, time.RFC3339, "72h", "14d")
 }
 
 // FindChartURL will return the chart url
@@ -314,6 +337,20 @@ func EncodeSha1(s string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// FileDigest returns the SHA256 digest of a file, like "sha256:<hex>"
+func FileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Trace(err)
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
 // NormalizeChartURL forms the full download URL in case we pass a relative URL
 func NormalizeChartURL(repoURL, chartURL string) (string, error) {
 	if chartURL == "" {
@@ -358,6 +395,7 @@ type fetchOptions struct {
 	insecure        bool
 	statusHandlerFn statusHandler
 	urlBuilderFn    urlBuilder
+	suffix          string
 }
 
 // FetchOption defines a fetchOptions setting
@@ -398,6 +436,14 @@ func WithFetchURLBuilder(h urlBuilder) FetchOption {
 	}
 }
 
+// WithFetchSuffix configures a suffix appended to the chart URL, to fetch a
+// file published along with the chart, i.e ".prov"
+func WithFetchSuffix(suffix string) FetchOption {
+	return func(opts *fetchOptions) {
+		opts.suffix = suffix
+	}
+}
+
 var defaultStatusHandler = func(res *http.Response) error {
 	if ok := res.StatusCode >= 200 && res.StatusCode <= 299; !ok {
 		bodyStr := HTTPResponseBody(res)
@@ -408,16 +454,16 @@ var defaultStatusHandler = func(res *http.Response) error {
 
 // FetchAndCache fetches a chart and stores it in provided cache
 func FetchAndCache(name, version string, cache cache.Cacher, fopts ...FetchOption) (string, error) {
-	id := fmt.Sprintf("%s-%s.tgz", name, version)
-	if cache.Has(id) {
-		return cache.Path(id), nil
-	}
-
 	opts := fetchOptions{statusHandlerFn: defaultStatusHandler}
 	for _, opt := range fopts {
 		opt(&opts)
 	}
 
+	id := fmt.Sprintf("%s-%s.tgz%s", name, version, opts.suffix)
+	if cache.Has(id) {
+		return cache.Path(id), nil
+	}
+
 	if opts.urlBuilderFn == nil {
 		return "", fmt.Errorf("requires a download URL builder")
 	}
@@ -426,6 +472,7 @@ func FetchAndCache(name, version string, cache cache.Cacher, fopts ...FetchOptio
 	if err != nil {
 		return "", errors.Trace(err)
 	}
+	u += opts.suffix
 
 	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
@@ -105,14 +105,32 @@ func TestGetFileContentType(t *testing.T) {
 }
 
 func TestGetDateThreshold(t *testing.T) {
-	date := time.Date(2020, 05, 15, 0, 0, 0, 0, time.UTC)
-	fromDate := "2020-05-15"
-	dateThreshold, err := GetDateThreshold(fromDate)
-	if err != nil {
-		t.Fatal(err)
+	now := time.Date(2020, 05, 20, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		date    string
+		want    time.Time
+		wantErr bool
+	}{
+		{date: "", want: UnixEpoch},
+		{date: "2020-05-15", want: time.Date(2020, 05, 15, 0, 0, 0, 0, time.UTC)},
+		{date: "2020-05-15T10:00:00+02:00", want: time.Date(2020, 05, 15, 8, 0, 0, 0, time.UTC)},
+		{date: "72h", want: time.Date(2020, 05, 17, 10, 30, 0, 0, time.UTC)},
+		{date: "1h30m", want: time.Date(2020, 05, 20, 9, 0, 0, 0, time.UTC)},
+		{date: "14d", want: time.Date(2020, 05, 6, 10, 30, 0, 0, time.UTC)},
+		{date: "15/05/2020", wantErr: true},
+		{date: "-14d", wantErr: true},
+		{date: "-72h", wantErr: true},
 	}
-	if dateThreshold != date {
-		t.Errorf("incorrect dateThreshold, expected: %v, got %v", date, dateThreshold)
+	for _, tc := range tests {
+		t.Run(tc.date, func(t *testing.T) {
+			got, err := dateThreshold(tc.date, now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("dateThreshold() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("incorrect dateThreshold, expected: %v, got %v", tc.want, got)
+			}
+		})
 	}
 }
 
@@ -0,0 +1,155 @@
+// Package verify implements the verification of chart provenance files and
+// signatures
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/juju/errors"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of the layers of cosign
+	// signatures
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the layer annotation containing the base64
+	// encoded cosign signature of the layer payload
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// Keyring verifies Helm provenance files
+type Keyring struct {
+	signatory *provenance.Signatory
+}
+
+// NewKeyring loads the keyring in path
+func NewKeyring(path string) (*Keyring, error) {
+	sig, err := provenance.NewFromKeyring(path, "")
+	if err != nil {
+		return nil, errors.Annotatef(err, "loading %q keyring", path)
+	}
+	return &Keyring{signatory: sig}, nil
+}
+
+// Verify verifies that the provenance file in provPath is signed by a key of
+// the keyring, and that it matches the chart in chartPath
+func (k *Keyring) Verify(chartPath, provPath string) error {
+	if _, err := k.signatory.Verify(chartPath, provPath); err != nil {
+		return errors.Annotatef(err, "verifying %q provenance", chartPath)
+	}
+	return nil
+}
+
+// PublicKey verifies cosign signatures
+type PublicKey struct {
+	key crypto.PublicKey
+}
+
+// LoadPublicKey loads the PEM encoded public key in path
+func LoadPublicKey(path string) (*PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("%q is not a PEM encoded public key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Annotatef(err, "parsing %q public key", path)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, errors.Errorf("%q public key type %T is not supported", path, key)
+	}
+	return &PublicKey{key: key}, nil
+}
+
+// simpleSigningPayload is the payload signed by cosign
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify verifies that at least one of the cosign signatures is made with the
+// key over the manifest with the provided digest
+func (k *PublicKey) Verify(digest string, signatures []v1.Image) error {
+	for _, sig := range signatures {
+		m, err := sig.Manifest()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, l := range m.Layers {
+			if l.MediaType != SimpleSigningMediaType {
+				continue
+			}
+			encoded, ok := l.Annotations[SignatureAnnotation]
+			if !ok {
+				continue
+			}
+			signature, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				continue
+			}
+			layer, err := sig.LayerByDigest(l.Digest)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			payload, err := readLayer(layer)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !k.verifySignature(payload, signature) {
+				continue
+			}
+			var p simpleSigningPayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				continue
+			}
+			if p.Critical.Image.DockerManifestDigest == digest {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf("no valid signature found for %s", digest)
+}
+
+// verifySignature returns whether signature is a valid signature of payload
+func (k *PublicKey) verifySignature(payload, signature []byte) bool {
+	h := sha256.Sum256(payload)
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, h[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	}
+	return false
+}
+
+func readLayer(l v1.Layer) ([]byte, error) {
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
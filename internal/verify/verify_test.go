@@ -0,0 +1,83 @@
+package verify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestKeyringVerify(t *testing.T) {
+	k, err := NewKeyring("../../testdata/provenance/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Verify("../../testdata/provenance/hashtest-1.2.3.tgz", "../../testdata/provenance/hashtest-1.2.3.tgz.prov"); err != nil {
+		t.Errorf("unexpected verification error: %v", err)
+	}
+	// The provenance file does not match other charts
+	if err := k.Verify("../../testdata/apache-7.3.15.tgz", "../../testdata/provenance/hashtest-1.2.3.tgz.prov"); err == nil {
+		t.Errorf("expected verification error for a chart not matching the provenance file")
+	}
+}
+
+func TestPublicKeyVerify(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	k, err := LoadPublicKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const digest = "sha256:4a5f9c2f3d8e1b0a6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b"
+	sign := func(digest string) v1.Image {
+		payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"example.com/charts/apache"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+		h := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := mutate.Append(empty.Image, mutate.Addendum{
+			Layer:       static.NewLayer(payload, ggcrtypes.MediaType(SimpleSigningMediaType)),
+			Annotations: map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+
+	if err := k.Verify(digest, []v1.Image{sign(digest)}); err != nil {
+		t.Errorf("unexpected verification error: %v", err)
+	}
+	// Signatures of other manifests are not valid
+	if err := k.Verify(digest, []v1.Image{sign("sha256:0000000000000000000000000000000000000000000000000000000000000000")}); err == nil {
+		t.Errorf("expected verification error for a signature of another manifest")
+	}
+	if err := k.Verify(digest, nil); err == nil {
+		t.Errorf("expected verification error without signatures")
+	}
+}
@@ -2,6 +2,7 @@
 package client
 
 import (
+	"github.com/bitnami/charts-syncer/internal/sign"
 	"github.com/bitnami/charts-syncer/pkg/client/config"
 	"github.com/bitnami/charts-syncer/pkg/client/types"
 	"helm.sh/helm/v3/pkg/chart"
@@ -33,6 +34,34 @@ type ChartsReaderWriter interface {
 	ChartsWriter
 }
 
+// ArtifactsReader defines the methods that a client able to read the artifacts attached to charts, like
+// signatures, SBOMs or attestations, should implement.
+type ArtifactsReader interface {
+	ListArtifacts(name string, version string) ([]*types.Artifact, error)
+}
+
+// ArtifactsWriter defines the methods that a client able to attach artifacts to charts should implement.
+type ArtifactsWriter interface {
+	PushArtifacts(name string, version string, artifacts []*types.Artifact) error
+}
+
+// ProvenanceReader defines the methods that a client able to read the provenance files (.prov) of charts should
+// implement.
+type ProvenanceReader interface {
+	FetchProvenance(name string, version string) (string, error)
+}
+
+// ProvenanceWriter defines the methods that a client able to store the provenance files (.prov) of charts should
+// implement.
+type ProvenanceWriter interface {
+	PushProvenance(name string, version string, provPath string) error
+}
+
+// ChartSigner defines the methods that a client able to sign the charts it stores should implement.
+type ChartSigner interface {
+	SignChart(name string, version string, signer *sign.Signer) error
+}
+
 // ChartsUnwrapper defines the methods required to unwrap a chart
 type ChartsUnwrapper interface {
 	ChartsReader
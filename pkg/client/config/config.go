@@ -2,6 +2,7 @@
 package config
 
 import (
+	"github.com/bitnami/charts-syncer/api"
 	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log"
 	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log/silent"
 )
@@ -12,6 +13,9 @@ type Config struct {
 	WorkDir            string
 	ContainerPlatforms []string
 	SkipArtifacts      bool
+	// ImageDiscovery are the methods to find the images of the charts not
+	// listing them in their "images" annotation
+	ImageDiscovery []api.ImageDiscovery
 }
 
 // Option is a function that modifies the Config
@@ -38,6 +42,14 @@ func WithContainerPlatforms(containerPlatforms []string) func(*Config) {
 	}
 }
 
+// WithImageDiscovery sets the methods to find the images of the charts not
+// listing them in their "images" annotation
+func WithImageDiscovery(methods []api.ImageDiscovery) func(*Config) {
+	return func(c *Config) {
+		c.ImageDiscovery = methods
+	}
+}
+
 // WithLogger sets the logger
 func WithLogger(logger log.SectionLogger) func(*Config) {
 	return func(c *Config) {
@@ -134,6 +134,11 @@ func (r *Repo) Fetch(name string, version string) (string, error) {
 	return r.helm.Fetch(name, version)
 }
 
+// FetchProvenance downloads the provenance file of a chart from the repo
+func (r *Repo) FetchProvenance(name string, version string) (string, error) {
+	return r.helm.FetchProvenance(name, version)
+}
+
 // List lists all chart names in the repo
 func (r *Repo) List() ([]string, error) {
 	return r.helm.List()
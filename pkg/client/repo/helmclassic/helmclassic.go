@@ -167,6 +167,33 @@ func (r *Repo) Fetch(name string, version string) (string, error) {
 	return chartPath, nil
 }
 
+// FetchProvenance fetches the provenance file (.prov) of a chart. A not found
+// error is returned if the chart is not signed.
+func (r *Repo) FetchProvenance(name string, version string) (string, error) {
+	fetchOpts := []utils.FetchOption{
+		utils.WithFetchUsername(r.username),
+		utils.WithFetchPassword(r.password),
+		utils.WithFetchInsecure(r.insecure),
+		utils.WithFetchURLBuilder(r.GetDownloadURL),
+		utils.WithFetchSuffix(".prov"),
+		utils.WithFetchStatusHandler(func(res *http.Response) error {
+			if res.StatusCode == http.StatusNotFound {
+				return errors.NotFoundf("%s:%s provenance file", name, version)
+			}
+			if ok := res.StatusCode >= 200 && res.StatusCode <= 299; !ok {
+				return errors.Errorf("got HTTP Status: %s, Resp: %v", res.Status, utils.HTTPResponseBody(res))
+			}
+			return nil
+		}),
+	}
+	provPath, err := utils.FetchAndCache(name, version, r.cache, fetchOpts...)
+	if err != nil {
+		return "", errors.Annotatef(err, "fetching %s:%s provenance file", name, version)
+	}
+
+	return provPath, nil
+}
+
 // Has checks if a repo has a specific chart
 func (r *Repo) Has(name string, version string) (bool, error) {
 	versions, err := r.ListChartVersions(name)
@@ -202,6 +229,8 @@ func (r *Repo) GetChartDetails(name string, version string) (*types.ChartDetails
 	return &types.ChartDetails{
 		PublishedAt: cv.Created,
 		Digest:      cv.Digest,
+		Deprecated:  cv.Deprecated,
+		Annotations: cv.Annotations,
 	}, nil
 }
 
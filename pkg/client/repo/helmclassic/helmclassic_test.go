@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/juju/errors"
 	"helm.sh/helm/v3/pkg/time"
 
 	"github.com/bitnami/charts-syncer/api"
@@ -88,6 +89,14 @@ func TestFetch(t *testing.T) {
 	}
 }
 
+func TestFetchProvenance(t *testing.T) {
+	c := prepareTest(t, "index.yaml")
+	// The testdata charts are not signed
+	if _, err := c.FetchProvenance("etcd", "4.8.0"); !errors.IsNotFound(err) {
+		t.Errorf("got error %v, want a not found error", err)
+	}
+}
+
 func TestHas(t *testing.T) {
 	c := prepareTest(t, "index.yaml")
 	has, err := c.Has("etcd", "4.8.0")
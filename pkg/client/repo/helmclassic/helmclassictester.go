@@ -152,7 +152,6 @@ func (rt *RepoTester) GetIndex(w http.ResponseWriter, _ *http.Request, emptyInde
 
 // GetChartPackage returns a packaged helm chart
 func (rt *RepoTester) GetChartPackage(w http.ResponseWriter, _ *http.Request, chartPackageName string) {
-	w.WriteHeader(200)
 	_, filename, _, ok := runtime.Caller(1)
 	if !ok {
 		rt.t.Fatal("couldn't get caller filename")
@@ -161,9 +160,15 @@ func (rt *RepoTester) GetChartPackage(w http.ResponseWriter, _ *http.Request, ch
 	// Get chart from testdata folder
 	chartPackageFile := path.Join(testdataPath, "charts", chartPackageName)
 	chartPackage, err := os.ReadFile(chartPackageFile)
+	// Charts are not signed unless there is a provenance file in the testdata folder
+	if os.IsNotExist(err) && strings.HasSuffix(chartPackageName, ".prov") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		rt.t.Fatal(err)
 	}
+	w.WriteHeader(200)
 	w.Write(chartPackage)
 }
 
@@ -14,10 +14,13 @@ import (
 	"github.com/bitnami/charts-syncer/internal/utils"
 	"github.com/bitnami/charts-syncer/pkg/client/types"
 	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/klog"
 )
 
 var (
-	versionRe = regexp.MustCompile(`(.*)-(\d+\.\d+\.\d+)(\.wrap)?\.tgz`)
+	// versionRe matches the chart name and version of a bundle file name,
+	// including pre-release and build suffixes, like "kafka-1.0.0+mirror.wrap.tgz"
+	versionRe = regexp.MustCompile(`^(.+?)-(\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]*?)?)(\.wrap)?\.tgz$`)
 )
 
 // Repo allows to operate a chart repository.
@@ -45,8 +48,12 @@ func New(dir string) (*Repo, error) {
 	for _, m := range matches {
 		filename := filepath.Base(m)
 		s := versionRe.FindStringSubmatch(filename)
+		if s == nil {
+			klog.V(4).Infof("Ignoring %q file: not a chart bundle", m)
+			continue
+		}
 		entries[s[1]] = append(entries[s[1]], s[2])
-		sort.Strings(entries[s[0]])
+		sort.Strings(entries[s[1]])
 	}
 
 	return &Repo{dir: d, entries: entries}, nil
@@ -136,6 +143,33 @@ func (r *Repo) GetChartDetails(_ string, _ string) (*types.ChartDetails, error)
 	}, nil
 }
 
+// FetchProvenance returns the provenance file of a chart, stored beside the
+// chart bundle. A not found error is returned if the chart has none.
+func (r *Repo) FetchProvenance(name string, version string) (string, error) {
+	prov := r.provenancePath(name, version)
+	if _, err := os.Stat(prov); os.IsNotExist(err) {
+		return "", errors.NotFoundf("%s:%s provenance file", name, version)
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	return prov, nil
+}
+
+// PushProvenance stores the provenance file of a chart beside the chart
+// bundle
+func (r *Repo) PushProvenance(name string, version string, provPath string) error {
+	if err := utils.CopyFile(r.provenancePath(name, version), provPath); err != nil {
+		return errors.Annotatef(err, "copying %q", provPath)
+	}
+	return nil
+}
+
+// provenancePath returns the path of the provenance file of a chart. It is
+// named after the chart package it signs.
+func (r *Repo) provenancePath(name string, version string) string {
+	return path.Join(r.dir, fmt.Sprintf("%s-%s.tgz.prov", name, version))
+}
+
 // Reload reloads the index
 func (r *Repo) Reload() error {
 	return nil
@@ -2,12 +2,14 @@ package local_test
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
 
 	"github.com/bitnami/charts-syncer/pkg/client/repo/local"
 	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/juju/errors"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/time"
 )
@@ -77,6 +79,28 @@ func TestListChartVersions(t *testing.T) {
 	}
 }
 
+func TestListChartVersionsSuffixed(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"my-chart-1.0.0.wrap.tgz", "my-chart-1.0.0-mirror.wrap.tgz", "my-chart-1.1.0+mirror.1.wrap.tgz", "notes.wrap.tgz"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := local.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.0.0", "1.0.0-mirror", "1.1.0+mirror.1"}
+	got, err := c.ListChartVersions("my-chart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected list of versions. got: %v, want: %v", got, want)
+	}
+}
+
 func TestGetChartDetails(t *testing.T) {
 	c, err := local.New("../../../../testdata/wraps")
 	if err != nil {
@@ -116,3 +140,23 @@ func TestUpload(t *testing.T) {
 		t.Errorf("error cleaning chart path from %q after successful upload", expectedChartPath)
 	}
 }
+
+func TestProvenance(t *testing.T) {
+	c, err := local.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.FetchProvenance("hashtest", "1.2.3"); !errors.Is(err, errors.NotFound) {
+		t.Errorf("got error %v, want a not found error", err)
+	}
+	if err := c.PushProvenance("hashtest", "1.2.3", "../../../../testdata/provenance/hashtest-1.2.3.tgz.prov"); err != nil {
+		t.Fatal(err)
+	}
+	prov, err := c.FetchProvenance("hashtest", "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(c.Dir(), "hashtest-1.2.3.tgz.prov"); prov != want {
+		t.Errorf("unexpected provenance file. got: %q, want: %q", prov, want)
+	}
+}
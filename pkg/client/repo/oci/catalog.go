@@ -0,0 +1,128 @@
+package oci
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	"github.com/juju/errors"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/klog"
+)
+
+const (
+	// catalogWorkers is the number of repositories inspected concurrently
+	catalogWorkers = 8
+	// catalogTimeout is the maximum time to discover the charts of a registry
+	catalogTimeout = 30 * time.Minute
+)
+
+// discover populates the entries with the charts found walking the registry
+// catalog. The catalog is only walked once.
+func (r *Repo) discover() error {
+	r.discoverOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+		defer cancel()
+
+		klog.Infof("Discovering charts from the %q registry catalog...", r.url.Host)
+		entries, err := r.discoverCharts(ctx)
+		if err != nil {
+			r.discoverErr = errors.Annotatef(err, "discovering charts from the %q registry catalog", r.url.Host)
+			return
+		}
+		klog.Infof("Found %d charts in the %q registry catalog", len(entries), r.url.Host)
+		r.entries = entries
+	})
+	return r.discoverErr
+}
+
+// discoverCharts returns the versions of the charts found under the repo path
+// in the registry catalog. Repositories are classified as charts by the config
+// media type of their tags.
+func (r *Repo) discoverCharts(ctx context.Context) (map[string][]string, error) {
+	u := *r.url
+	if r.usePlainHTTP {
+		u.Scheme = "http"
+	}
+	opts := []indexer.OciIndexerOpt{
+		indexer.WithHost(u.String()),
+		indexer.WithBasicAuth(r.username, r.password),
+	}
+	if r.insecure {
+		opts = append(opts, indexer.WithInsecure())
+	}
+	names, err := indexer.Repositories(ctx, opts...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	prefix := strings.Trim(r.url.Path, "/")
+	klog.V(3).Infof("Inspecting %d repositories under %q", len(names), prefix)
+
+	var mu sync.Mutex
+	entries := make(map[string][]string)
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(catalogWorkers)
+	for _, n := range names {
+		n := n
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			versions, err := r.discoverVersions(n)
+			if err != nil {
+				// A broken repository should not prevent discovering the rest
+				klog.Warningf("Skipping %q repository: %v", n, err)
+				return nil
+			}
+			if len(versions) > 0 {
+				mu.Lock()
+				entries[n] = versions
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return entries, nil
+}
+
+// discoverVersions returns the chart versions of a repository. Tags are
+// inspected until one is a chart, skipping other artifacts like signatures or
+// attestations. Repositories of container images are skipped as soon as an
+// image is found, without inspecting the rest of tags.
+func (r *Repo) discoverVersions(chartName string) ([]string, error) {
+	tags, err := r.listTags(chartName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, tag := range tags {
+		tm, err := r.getTagManifest(chartName, tag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if tm.Config.MediaType == HelmChartConfigMediaType {
+			return r.chartTags(chartName, tags)
+		}
+		if isContainerImage(tm) {
+			break
+		}
+	}
+	klog.V(5).Infof("Skipping %q repository as it does not contain charts", chartName)
+	return nil, nil
+}
+
+// isContainerImage returns whether a manifest is a container image, with
+// filesystem layers
+func isContainerImage(m *ocispec.Manifest) bool {
+	for _, l := range m.Layers {
+		if strings.Contains(l.MediaType, ".image.layer.") || strings.Contains(l.MediaType, ".image.rootfs.") {
+			return true
+		}
+	}
+	return false
+}
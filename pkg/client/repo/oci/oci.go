@@ -3,7 +3,6 @@ package oci
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/containerd/containerd/remotes"
@@ -33,6 +33,7 @@ import (
 	"github.com/bitnami/charts-syncer/api"
 	"github.com/bitnami/charts-syncer/internal/cache"
 	"github.com/bitnami/charts-syncer/internal/indexer"
+	indexapi "github.com/bitnami/charts-syncer/internal/indexer/api"
 	"github.com/bitnami/charts-syncer/internal/utils"
 	"github.com/bitnami/charts-syncer/pkg/client/types"
 )
@@ -45,6 +46,8 @@ const (
 	// HelmChartContentLayerMediaTypeDeprecated is the (deprecated) reserved media type for Helm
 	// chart package content
 	HelmChartContentLayerMediaTypeDeprecated = "application/tar+gzip"
+	// HelmChartProvenanceLayerMediaType is the reserved media type for Helm chart provenance files
+	HelmChartProvenanceLayerMediaType = "application/vnd.cncf.helm.chart.provenance.v1.prov"
 	// ImageManifestMediaType is the reserved media type for OCI manifests
 	ImageManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
 )
@@ -57,9 +60,20 @@ type Repo struct {
 	insecure     bool
 	usePlainHTTP bool
 
-	entries        map[string][]string
+	entries map[string][]string
+	// indexed is the metadata of the charts in the index, indexed by
+	// indexKey
+	indexed        map[string]*indexapi.ChartMetadata
 	cache          cache.Cacher
 	dockerResolver remotes.Resolver
+
+	// catalogDiscovery enables discovering the charts from the registry
+	// catalog if there is no charts index
+	catalogDiscovery bool
+	discoverOnce     sync.Once
+	discoverErr      error
+	// manifests caches the manifests of the tags, indexed by reference
+	manifests sync.Map
 }
 
 // Tags contains the tags for a specific OCI artifact
@@ -71,7 +85,7 @@ type Tags struct {
 // New creates a Repo object from an api.Repo object.
 func New(repo *api.Repo, c cache.Cacher, insecure bool, usePlainHTTP bool) (*Repo, error) {
 	// Init entries
-	entries, err := populateEntries(repo)
+	entries, indexed, err := populateEntries(repo)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -82,7 +96,13 @@ func New(repo *api.Repo, c cache.Cacher, insecure bool, usePlainHTTP bool) (*Rep
 	}
 	resolver := newDockerResolver(u, repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword(), insecure)
 
-	return NewRaw(u, repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword(), c, insecure, usePlainHTTP, entries, resolver)
+	r, err := NewRaw(u, repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword(), c, insecure, usePlainHTTP, entries, resolver)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	r.catalogDiscovery = repo.GetCatalogDiscovery()
+	r.indexed = indexed
+	return r, nil
 }
 
 // NewRaw creates a Repo object.
@@ -92,6 +112,12 @@ func NewRaw(u *url.URL, user string, pass string, c cache.Cacher, insecure bool,
 
 // List lists all chart names in a repo
 func (r *Repo) List() ([]string, error) {
+	if len(r.entries) == 0 && r.catalogDiscovery {
+		if err := r.discover(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	// If entries is not populated, it means we couldn't load any index file, so we need the charts filter in the
 	// configuration file. The List() caller will handle this case
 	if len(r.entries) == 0 {
@@ -116,17 +142,16 @@ func (r *Repo) getTagManifest(chartName, version string) (*ocispec.Manifest, err
 		return nil, errors.Errorf("failed parsing OCI reference: %s", err)
 	}
 
-	opts := []remote.Option{}
-	if r.username != "" && r.password != "" {
-		opts = append(opts, remote.WithAuth(&authn.Basic{
-			Username: r.username,
-			Password: r.password,
-		}))
-	}
-	if r.insecure {
-		transport := http.DefaultTransport.(*http.Transport).Clone()
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
-		opts = append(opts, remote.WithTransport(transport))
+	return r.getManifest(ref)
+}
+
+// getManifest returns the manifest of a reference, by tag or by digest
+func (r *Repo) getManifest(ref name.Reference) (*ocispec.Manifest, error) {
+	opts := r.remoteOptions()
+
+	// Manifests are requested several times while discovering and indexing charts
+	if tm, ok := r.manifests.Load(ref.String()); ok {
+		return tm.(*ocispec.Manifest), nil
 	}
 
 	image, err := remote.Image(ref, opts...)
@@ -143,6 +168,7 @@ func (r *Repo) getTagManifest(chartName, version string) (*ocispec.Manifest, err
 	if err := json.Unmarshal(body, tm); err != nil {
 		return nil, err
 	}
+	r.manifests.Store(ref.String(), tm)
 	return tm, nil
 }
 
@@ -152,13 +178,18 @@ func (r *Repo) getChartDigest(name, version string) (string, error) {
 	if err != nil {
 		return "", errors.Trace(err)
 	}
+	return chartDigest(tm, fmt.Sprintf("%s:%s", name, version))
+}
+
+// chartDigest returns the digest of the chart content layer of a manifest
+func chartDigest(tm *ocispec.Manifest, id string) (string, error) {
 	for _, layer := range tm.Layers {
 		if isHelmChartContentLayerMediaType(layer.MediaType) {
 			return layer.Digest.String(), nil
 		}
 	}
 
-	return "", errors.NotFoundf("%s:%s digest", name, version)
+	return "", errors.NotFoundf("%s digest", id)
 }
 
 // ListChartVersions lists all versions of a chart
@@ -170,6 +201,15 @@ func (r *Repo) ListChartVersions(chartName string) ([]string, error) {
 		return r.entries[chartName], nil
 	}
 
+	tags, err := r.listTags(chartName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r.chartTags(chartName, tags)
+}
+
+// listTags lists all the tags of a repository
+func (r *Repo) listTags(chartName string) ([]string, error) {
 	u := *r.url
 	u.Path = path.Join(u.Path, "/", chartName)
 
@@ -178,24 +218,17 @@ func (r *Repo) ListChartVersions(chartName string) ([]string, error) {
 		return nil, fmt.Errorf("failed to parse repo %v", err)
 	}
 
-	opts := []remote.Option{}
-	if r.username != "" && r.password != "" {
-		opts = append(opts, remote.WithAuth(&authn.Basic{
-			Username: r.username,
-			Password: r.password,
-		}))
-	}
-	if r.insecure {
-		transport := http.DefaultTransport.(*http.Transport).Clone()
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
-		opts = append(opts, remote.WithTransport(transport))
-	}
+	opts := r.remoteOptions()
 
 	tags, err := remote.List(repo, opts...)
 	if err != nil {
 		return nil, errors.Errorf("failed to fetch tags for %q: %v", repo, err)
 	}
+	return tags, nil
+}
 
+// chartTags returns the tags of a repository that are charts
+func (r *Repo) chartTags(chartName string, tags []string) ([]string, error) {
 	chartTags := []string{}
 	for _, tag := range tags {
 		tm, err := r.getTagManifest(chartName, tag)
@@ -227,18 +260,7 @@ func (r *Repo) Fetch(chartName string, version string) (string, error) {
 		return "", errors.Errorf("failed parsing OCI reference: %s", err)
 	}
 
-	opts := []remote.Option{}
-	if r.username != "" && r.password != "" {
-		opts = append(opts, remote.WithAuth(&authn.Basic{
-			Username: r.username,
-			Password: r.password,
-		}))
-	}
-	if r.insecure {
-		transport := http.DefaultTransport.(*http.Transport).Clone()
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
-		opts = append(opts, remote.WithTransport(transport))
-	}
+	opts := r.remoteOptions()
 
 	img, err := remote.Image(ref, opts...)
 	if err != nil {
@@ -300,18 +322,7 @@ func (r *Repo) Has(chartName string, version string) (bool, error) {
 		return false, errors.Errorf("failed parsing OCI reference: %s", err)
 	}
 
-	opts := []remote.Option{}
-	if r.username != "" && r.password != "" {
-		opts = append(opts, remote.WithAuth(&authn.Basic{
-			Username: r.username,
-			Password: r.password,
-		}))
-	}
-	if r.insecure {
-		transport := http.DefaultTransport.(*http.Transport).Clone()
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
-		opts = append(opts, remote.WithTransport(transport))
-	}
+	opts := r.remoteOptions()
 
 	_, err = remote.Head(ref, opts...)
 	if err != nil {
@@ -376,7 +387,9 @@ func (r *Repo) Upload(file string, metadata *chart.Metadata) error {
 		return errors.Trace(err)
 	}
 
-	manifest, manifestDesc, err := content.GenerateManifest(&configDesc, nil, blobDesc)
+	// Record the publishing date like helm does, so it can be filtered by date
+	annotations := map[string]string{ocispec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339)}
+	manifest, manifestDesc, err := content.GenerateManifest(&configDesc, annotations, blobDesc)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -397,21 +410,68 @@ func (r *Repo) Upload(file string, metadata *chart.Metadata) error {
 	return nil
 }
 
-// GetChartDetails returns the details of a chart
+// GetChartDetails returns the details of a chart. The digest is always read
+// from the registry, so it can be checked against the one in the index, while
+// the publishing date, the deprecation and the annotations come from the index
+// if the chart is in it.
 func (r *Repo) GetChartDetails(name string, version string) (*types.ChartDetails, error) {
 	digest, err := r.getChartDigest(name, version)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	publishedAt, err := r.getPublishingDate(name, version)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return &types.ChartDetails{
-		// OCI registries does not provide info about the publishing date in any API endpoint.
-		// Therefore we cannot use the --from-date and we should publish everything.
-		// Setting today's date so they get published.
-		PublishedAt: time.Now(),
+		PublishedAt: publishedAt,
 		Digest:      digest,
+		Deprecated:  r.indexed[indexKey(name, version)].GetDeprecated(),
+		Annotations: r.indexed[indexKey(name, version)].GetAnnotations(),
 	}, nil
 }
 
+// getPublishingDate returns when a chart was published, from the charts index
+// or from the creation annotation of its manifest.
+// OCI registries do not provide info about the publishing date in any API
+// endpoint, so charts without any of them get today's date to be published
+// regardless of --from-date.
+func (r *Repo) getPublishingDate(name, version string) (time.Time, error) {
+	if t, ok := releaseDate(r.indexed[indexKey(name, version)]); ok {
+		return t, nil
+	}
+	tm, err := r.getTagManifest(name, version)
+	if err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	if created, ok := tm.Annotations[ocispec.AnnotationCreated]; ok {
+		t, err := time.Parse(time.RFC3339, created)
+		if err == nil {
+			return t, nil
+		}
+		klog.V(4).Infof("invalid %q creation date of %s:%s: %v", created, name, version, err)
+	}
+	return time.Now(), nil
+}
+
+// releaseDate returns when an indexed chart was published, and false if the
+// index does not tell
+func releaseDate(m *indexapi.ChartMetadata) (time.Time, bool) {
+	if m.GetReleasedAt() == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, m.GetReleasedAt())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// indexKey returns the key of a chart version in the indexed charts of the repo
+func indexKey(name, version string) string {
+	return name + ":" + version
+}
+
 // Reload reloads the index
 func (r *Repo) Reload() error {
 	return errors.Errorf("reload method is not supported yet")
@@ -447,20 +507,21 @@ func ociReferenceExists(ociRef, username, password string) (bool, error) {
 	return true, nil
 }
 
-// populateEntries populates the entries map with the info from the charts index
-func populateEntries(repo *api.Repo) (map[string][]string, error) {
+// populateEntries populates the entries map with the info from the charts index,
+// and returns the metadata of the indexed charts
+func populateEntries(repo *api.Repo) (map[string][]string, map[string]*indexapi.ChartMetadata, error) {
 	if repo.GetDisableChartsIndex() {
-		return make(map[string][]string), nil
+		return make(map[string][]string), nil, nil
 	}
 
 	klog.Infof("Attempting to retrieve remote index...")
-	ind, err := indexer.NewOciIndexer(
+	ind, err := indexer.New(
 		indexer.WithHost(repo.GetUrl()),
 		indexer.WithBasicAuth(repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword()),
 		indexer.WithIndexRef(repo.GetChartsIndex()),
 	)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -471,19 +532,24 @@ func populateEntries(repo *api.Repo) (map[string][]string, error) {
 		// Since we automatically attempt to retrieve the index, let's not fail if not exists
 		if indexer.IsNotFound(err) {
 			klog.Warningf("The remote index does not exist yet. This process might be slow.")
-			return make(map[string][]string), nil
+			return make(map[string][]string), nil, nil
 		}
 
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	entries := make(map[string][]string, len(ociIndex.GetEntries()))
+	indexed := make(map[string]*indexapi.ChartMetadata)
 	for _, c := range ociIndex.GetEntries() {
 		for _, v := range c.GetVersions() {
 			entries[v.Name] = append(entries[v.Name], v.Version)
+			if _, ok := releaseDate(v); !ok && v.GetReleasedAt() != "" {
+				klog.Warningf("Ignoring the invalid %q release date of %s:%s in the index", v.GetReleasedAt(), v.Name, v.Version)
+			}
+			indexed[indexKey(v.Name, v.Version)] = v
 		}
 	}
-	return entries, nil
+	return entries, indexed, nil
 }
 
 func newDockerResolver(u *url.URL, username, password string, insecure bool) remotes.Resolver {
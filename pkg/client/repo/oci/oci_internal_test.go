@@ -7,9 +7,14 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/bitnami/charts-syncer/api"
+	indexapi "github.com/bitnami/charts-syncer/internal/indexer/api"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"google.golang.org/protobuf/proto"
+	"helm.sh/helm/v3/pkg/chart"
 )
 
 var (
@@ -86,3 +91,74 @@ func TestListWithEntries(t *testing.T) {
 		t.Errorf("unexpected list of charts names. got: %v, want: %v", got, want)
 	}
 }
+
+func TestGetPublishingDateFromIndex(t *testing.T) {
+	released := time.Date(2022, 1, 12, 12, 49, 54, 381313000, time.UTC)
+	// Charts in the index are not looked up in the registry
+	repo := Repo{
+		indexed: map[string]*indexapi.ChartMetadata{
+			indexKey("apache", "8.10.1"): {
+				Name:       "apache",
+				Version:    "8.10.1",
+				ReleasedAt: "2022-01-12T12:49:54.381313Z",
+			},
+		},
+	}
+	got, err := repo.getPublishingDate("apache", "8.10.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(released) {
+		t.Errorf("got %v publishing date, want %v", got, released)
+	}
+}
+
+func TestGetChartDetailsWithIndex(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	PrepareOCIServer(ctx, t, ociRepo)
+	repo := proto.Clone(ociRepo).(*api.Repo)
+	repo.DisableChartsIndex = true
+	r := PrepareTest(t, repo)
+	if err := r.Upload("../../../../testdata/apache-7.3.15.wrap.tgz", &chart.Metadata{Name: "apache", Version: "7.3.15"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index may be outdated, so the digest is read from the registry
+	stale := "sha256:34d7e5e1fd652066aa1583a89f3823d355696f2df2953fca987df49fffa962d5"
+	r.indexed = map[string]*indexapi.ChartMetadata{
+		indexKey("apache", "7.3.15"): {Name: "apache", Version: "7.3.15", Digest: stale, Deprecated: true, Annotations: map[string]string{"category": "Infrastructure"}},
+	}
+	details, err := r.GetChartDetails("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Digest == "" || details.Digest == stale {
+		t.Errorf("got %q digest, want the one in the registry", details.Digest)
+	}
+	if !details.Deprecated {
+		t.Errorf("want the chart deprecated by the index")
+	}
+	if want := map[string]string{"category": "Infrastructure"}; !reflect.DeepEqual(details.Annotations, want) {
+		t.Errorf("got %v annotations, want the ones in the index %v", details.Annotations, want)
+	}
+}
+
+func TestIsContainerImage(t *testing.T) {
+	tests := []struct {
+		layer string
+		want  bool
+	}{
+		{layer: "application/vnd.oci.image.layer.v1.tar+gzip", want: true},
+		{layer: "application/vnd.docker.image.rootfs.diff.tar.gzip", want: true},
+		{layer: "application/vnd.dev.cosign.simplesigning.v1+json"},
+		{layer: "application/vnd.dsse.envelope.v1+json"},
+		{layer: HelmChartContentLayerMediaType},
+	}
+	for _, tc := range tests {
+		m := &ocispec.Manifest{Layers: []ocispec.Descriptor{{MediaType: tc.layer}}}
+		if got := isContainerImage(m); got != tc.want {
+			t.Errorf("got %v for a %q layer, want %v", got, tc.layer, tc.want)
+		}
+	}
+}
@@ -1,16 +1,36 @@
 package oci_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/sign"
 	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/internal/verify"
 	"github.com/bitnami/charts-syncer/pkg/client/repo/oci"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/juju/errors"
+	"google.golang.org/protobuf/proto"
 	"helm.sh/helm/v3/pkg/chart"
 )
 
@@ -97,6 +117,49 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListWithCatalogDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := proto.Clone(ociRepo).(*api.Repo)
+	repo.CatalogDiscovery = true
+	oci.PrepareOCIServer(ctx, t, repo)
+	c := oci.PrepareTest(t, repo)
+
+	for _, ch := range []*chart.Metadata{{Name: "apache", Version: "7.3.15"}, {Name: "zookeeper", Version: "5.14.3"}} {
+		if err := c.Upload(fmt.Sprintf("../../../../testdata/%s-%s.wrap.tgz", ch.Name, ch.Version), ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Neither artifacts that are not charts nor repositories out of the repo
+	// path are discovered
+	u, err := url.Parse(repo.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oci.PushFileToOCI(t, "../../../../testdata/index.yaml", u.Host+u.Path+"/images/nginx:1.0.0")
+	oci.PushFileToOCI(t, "../../../../testdata/index.yaml", u.Host+"/otherproject/redis:1.0.0")
+
+	// A new client is required, as the catalog is only walked once
+	c = oci.PrepareTest(t, repo)
+	want := []string{"apache", "zookeeper"}
+	got, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected list of charts names. got: %v, want: %v", got, want)
+	}
+
+	versions, err := c.ListChartVersions("apache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual([]string{"7.3.15"}, versions) {
+		t.Errorf("unexpected list of chart versions. got: %v, want: %v", versions, []string{"7.3.15"})
+	}
+}
+
 func TestListChartVersions(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -162,3 +225,213 @@ func TestUpload(t *testing.T) {
 		t.Errorf("incorrect content type, got: %s, want: %s.", contentType, "application/x-gzip")
 	}
 }
+
+func TestGetChartDetails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oci.PrepareOCIServer(ctx, t, ociRepo)
+	c := oci.PrepareTest(t, ociRepo)
+	chartMetadata := &chart.Metadata{
+		Name:    "apache",
+		Version: "7.3.15",
+	}
+	before := time.Now().Truncate(time.Second)
+	if err := c.Upload("../../../../testdata/apache-7.3.15.wrap.tgz", chartMetadata); err != nil {
+		t.Fatal(err)
+	}
+
+	// The publishing date comes from the creation annotation of the manifest
+	details, err := c.GetChartDetails("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.PublishedAt.Before(before) || details.PublishedAt.After(time.Now()) {
+		t.Errorf("got %v publishing date, want the upload date", details.PublishedAt)
+	}
+	if details.Digest == "" {
+		t.Errorf("got an empty digest")
+	}
+}
+
+func TestListAndPushArtifacts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oci.PrepareOCIServer(ctx, t, ociRepo)
+	c := oci.PrepareTest(t, ociRepo)
+
+	for _, ch := range []*chart.Metadata{{Name: "apache", Version: "7.3.15"}, {Name: "zookeeper", Version: "5.14.3"}} {
+		if err := c.Upload(fmt.Sprintf("../../../../testdata/%s-%s.wrap.tgz", ch.Name, ch.Version), ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u, err := url.Parse(ociRepo.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewTag(u.Host + u.Path + "/apache:7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := remote.Head(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An SBOM attached with the referrers API, and a signature attached with
+	// the cosign tag schema
+	sbom, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbom = mutate.ConfigMediaType(sbom, "application/spdx+json")
+	sbom = mutate.Subject(sbom, *desc).(v1.Image)
+	sbomDigest, err := sbom.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Digest(sbomDigest.String()), sbom); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Tag(fmt.Sprintf("%s-%s.sig", desc.Digest.Algorithm, desc.Digest.Hex)), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := c.ListArtifacts("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"sbom", "signature"}
+	if got := artifactKinds(artifacts); !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected artifacts. got: %v, want: %v", got, want)
+	}
+
+	if err := c.PushArtifacts("zookeeper", "5.14.3", artifacts); err != nil {
+		t.Fatal(err)
+	}
+	copied, err := c.ListArtifacts("zookeeper", "5.14.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := artifactKinds(copied); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected copied artifacts. got: %v, want: %v", got, want)
+	}
+
+	none, err := c.ListArtifacts("apache", "7.3.15-unknown")
+	if err == nil {
+		t.Errorf("expected error listing the artifacts of a missing chart, got %v", none)
+	}
+}
+
+func TestSignChart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oci.PrepareOCIServer(ctx, t, ociRepo)
+	c := oci.PrepareTest(t, ociRepo)
+
+	if err := c.Upload("../../../../testdata/apache-7.3.15.wrap.tgz", &chart.Metadata{Name: "apache", Version: "7.3.15"}); err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privFile, pubFile := filepath.Join(dir, "cosign.key"), filepath.Join(dir, "cosign.pub")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := sign.LoadPrivateKey(privFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := verify.LoadPublicKey(pubFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SignChart("apache", "7.3.15", signer); err != nil {
+		t.Fatal(err)
+	}
+	artifacts, err := c.ListArtifacts("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"signature"}, artifactKinds(artifacts); !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected artifacts. got: %v, want: %v", got, want)
+	}
+	if err := pub.Verify(artifacts[0].Subject, []v1.Image{artifacts[0].Image}); err != nil {
+		t.Errorf("unexpected verification error: %v", err)
+	}
+	// The signature is tied to the chart content of the signed manifest
+	digest, err := utils.FileDigest("../../../../testdata/apache-7.3.15.wrap.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := artifacts[0].ChartDigest; got != digest {
+		t.Errorf("got %q chart digest, want %q", got, digest)
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oci.PrepareOCIServer(ctx, t, ociRepo)
+	c := oci.PrepareTest(t, ociRepo)
+
+	if err := c.Upload("../../../../testdata/apache-7.3.15.wrap.tgz", &chart.Metadata{Name: "apache", Version: "7.3.15"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.FetchProvenance("apache", "7.3.15"); !errors.Is(err, errors.NotFound) {
+		t.Errorf("got error %v, want a not found error", err)
+	}
+
+	const provFile = "../../../../testdata/provenance/hashtest-1.2.3.tgz.prov"
+	if err := c.PushProvenance("apache", "7.3.15", provFile); err != nil {
+		t.Fatal(err)
+	}
+	prov, err := c.FetchProvenance("apache", "7.3.15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(provFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("unexpected provenance file content: %s", got)
+	}
+
+	// The chart content is kept
+	if _, err := c.Fetch("apache", "7.3.15"); err != nil {
+		t.Errorf("unexpected error fetching the chart: %v", err)
+	}
+}
+
+func artifactKinds(artifacts []*types.Artifact) []string {
+	kinds := []string{}
+	for _, a := range artifacts {
+		kinds = append(kinds, a.Kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -137,6 +137,8 @@ func PrepareOCIServer(ctx context.Context, t *testing.T, ociRepo *api.Repo) {
 	config.HTTP.Addr = addr
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
 	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]interface{}{}}
+	// Default value when the configuration is parsed from a file
+	config.Catalog.MaxEntries = 1000
 	dockerRegistry, err := registry.NewRegistry(ctx, config)
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,110 @@
+package oci
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// FetchProvenance fetches the provenance file of a chart, stored as a layer
+// of the chart manifest. A not found error is returned if the chart has no
+// provenance file.
+func (r *Repo) FetchProvenance(chartName, version string) (string, error) {
+	id := fmt.Sprintf("%s-%s.tgz.prov", chartName, version)
+	if r.cache.Has(id) {
+		return r.cache.Path(id), nil
+	}
+
+	ref, err := r.chartRef(chartName, version)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	img, err := remote.Image(ref, r.remoteOptions()...)
+	if err != nil {
+		return "", errors.Annotatef(err, "failed checking remote: %s", ref)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+	}
+	for _, l := range layers {
+		t, err := l.MediaType()
+		if err != nil {
+			return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+		}
+		if t != HelmChartProvenanceLayerMediaType {
+			continue
+		}
+		c, err := l.Compressed()
+		if err != nil {
+			return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+		}
+		defer c.Close()
+
+		w, err := r.cache.Writer(id)
+		if err != nil {
+			return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+		}
+		if _, err := io.Copy(w, c); err != nil {
+			// Invalidate the cache
+			_ = r.cache.Invalidate(id)
+			return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+		}
+		if err := w.Close(); err != nil {
+			// Invalidate the cache
+			_ = r.cache.Invalidate(id)
+			return "", errors.Annotatef(err, "fetching %q provenance file", ref)
+		}
+		return r.cache.Path(id), nil
+	}
+	return "", errors.NotFoundf("%s:%s provenance file", chartName, version)
+}
+
+// PushProvenance adds a provenance file to a chart, as a layer of the chart
+// manifest like "helm push" does. Charts with a provenance file are kept as is. It changes the chart manifest digest, so it
+// has to be pushed before attaching artifacts to the chart.
+func (r *Repo) PushProvenance(chartName, version, provPath string) error {
+	ref, err := r.chartRef(chartName, version)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	prov, err := os.ReadFile(provPath)
+	if err != nil {
+		return errors.Annotatef(err, "reading %q", provPath)
+	}
+
+	opts := r.remoteOptions()
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return errors.Annotatef(err, "failed checking remote: %s", ref)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return errors.Annotatef(err, "fetching %q chart", ref)
+	}
+	for _, l := range layers {
+		if t, err := l.MediaType(); err == nil && t == HelmChartProvenanceLayerMediaType {
+			klog.V(3).Infof("%q already has a provenance file", ref)
+			return nil
+		}
+	}
+
+	img, err = mutate.Append(img, mutate.Addendum{
+		Layer: static.NewLayer(prov, ggcrtypes.MediaType(HelmChartProvenanceLayerMediaType)),
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := remote.Write(ref, img, opts...); err != nil {
+		return errors.Annotatef(err, "pushing %q", ref)
+	}
+	r.manifests.Delete(ref.String())
+	return nil
+}
@@ -0,0 +1,231 @@
+package oci
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+
+	"github.com/bitnami/charts-syncer/internal/sign"
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+)
+
+// cosignTagSuffixes are the suffixes of the tags cosign uses to attach
+// artifacts to a manifest when the referrers API is not used, and the kind of
+// artifact they contain
+var cosignTagSuffixes = []struct {
+	suffix string
+	kind   string
+}{
+	{".sig", types.ArtifactKindSignature},
+	{".att", types.ArtifactKindAttestation},
+	{".sbom", types.ArtifactKindSBOM},
+}
+
+// remoteOptions returns the options to access the registry
+func (r *Repo) remoteOptions() []remote.Option {
+	opts := []remote.Option{}
+	if r.username != "" && r.password != "" {
+		opts = append(opts, remote.WithAuth(&authn.Basic{
+			Username: r.username,
+			Password: r.password,
+		}))
+	}
+	return append(opts, remote.WithTransport(throttle.RegistryTransport(r.insecure)))
+}
+
+// chartRef returns the reference of a chart version
+func (r *Repo) chartRef(chartName, version string) (name.Tag, error) {
+	u := *r.url
+	u.Path = path.Join(u.Path, "/", chartName)
+
+	// helm replaces plus(+) characters with underscores(_) in the tag (version)
+	ref, err := name.NewTag(u.Host + u.Path + ":" + strings.ReplaceAll(version, "+", "_"))
+	if err != nil {
+		return name.Tag{}, errors.Errorf("failed parsing OCI reference: %s", err)
+	}
+	return ref, nil
+}
+
+// ListArtifacts lists the artifacts attached to a chart manifest, like cosign
+// signatures, SBOMs or attestations. Artifacts are discovered with the OCI
+// referrers API, which falls back to the referrers tag schema if the registry
+// does not support it, and with the cosign tag schema.
+func (r *Repo) ListArtifacts(chartName, version string) ([]*types.Artifact, error) {
+	ref, err := r.chartRef(chartName, version)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	opts := r.remoteOptions()
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return nil, errors.Annotatef(err, "failed checking remote: %s", ref)
+	}
+	subject := ref.Context().Digest(desc.Digest.String())
+	// The chart of the subject manifest is resolved by digest, so signatures
+	// can be tied to the chart content even if the tag moves
+	tm, err := r.getManifest(subject)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	chart, err := chartDigest(tm, subject.String())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	idx, err := remote.Referrers(subject, opts...)
+	if err != nil {
+		return nil, errors.Annotatef(err, "listing %q referrers", ref)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, errors.Annotatef(err, "listing %q referrers", ref)
+	}
+
+	var artifacts []*types.Artifact
+	for _, d := range im.Manifests {
+		if !d.MediaType.IsImage() {
+			klog.Warningf("Skipping %s artifact of %q as %q manifests are not supported", d.Digest, ref, d.MediaType)
+			continue
+		}
+		img, err := remote.Image(ref.Context().Digest(d.Digest.String()), opts...)
+		if err != nil {
+			return nil, errors.Annotatef(err, "fetching %s artifact of %q", d.Digest, ref)
+		}
+		artifacts = append(artifacts, &types.Artifact{
+			Kind:         artifactKind(d.ArtifactType),
+			Digest:       d.Digest.String(),
+			ArtifactType: d.ArtifactType,
+			Subject:      desc.Digest.String(),
+			ChartDigest:  chart,
+			Image:        img,
+		})
+	}
+
+	for _, t := range cosignTagSuffixes {
+		tag := ref.Context().Tag(cosignTag(desc.Digest, t.suffix))
+		img, err := remote.Image(tag, opts...)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Annotatef(err, "fetching %q", tag)
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			return nil, errors.Annotatef(err, "fetching %q", tag)
+		}
+		artifacts = append(artifacts, &types.Artifact{
+			Kind:        t.kind,
+			Digest:      digest.String(),
+			TagSuffix:   t.suffix,
+			Subject:     desc.Digest.String(),
+			ChartDigest: chart,
+			Image:       img,
+		})
+	}
+	return artifacts, nil
+}
+
+// PushArtifacts attaches artifacts to a chart manifest. Artifacts using the
+// referrers API are updated to refer to the chart manifest, as it may differ
+// from the one they were attached to in the source.
+func (r *Repo) PushArtifacts(chartName, version string, artifacts []*types.Artifact) error {
+	ref, err := r.chartRef(chartName, version)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	opts := r.remoteOptions()
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return errors.Annotatef(err, "failed checking remote: %s", ref)
+	}
+	subject := v1.Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size}
+
+	for _, a := range artifacts {
+		if a.TagSuffix != "" {
+			tag := ref.Context().Tag(cosignTag(desc.Digest, a.TagSuffix))
+			if err := remote.Write(tag, a.Image, opts...); err != nil {
+				return errors.Annotatef(err, "pushing %q", tag)
+			}
+			continue
+		}
+
+		img, err := withSubject(a.Image, subject)
+		if err != nil {
+			return errors.Annotatef(err, "attaching %s artifact to %q", a.Digest, ref)
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			return errors.Annotatef(err, "attaching %s artifact to %q", a.Digest, ref)
+		}
+		// The registry, or the client for registries without the referrers
+		// API, updates the referrers of the subject
+		if err := remote.Write(ref.Context().Digest(digest.String()), img, opts...); err != nil {
+			return errors.Annotatef(err, "attaching %s artifact to %q", a.Digest, ref)
+		}
+	}
+	return nil
+}
+
+// SignChart signs a chart manifest, pushing a cosign compatible signature
+func (r *Repo) SignChart(chartName, version string, signer *sign.Signer) error {
+	ref, err := r.chartRef(chartName, version)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tag, err := signer.SignRemote(ref, r.remoteOptions()...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	klog.V(3).Infof("%q signed in %q", ref, tag)
+	return nil
+}
+
+// withSubject returns img referring to subject. The image is not modified if
+// it already refers to it, so its digest is preserved.
+func withSubject(img v1.Image, subject v1.Descriptor) (v1.Image, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if m.Subject != nil && m.Subject.Digest == subject.Digest {
+		return img, nil
+	}
+	return mutate.Subject(img, subject).(v1.Image), nil
+}
+
+// cosignTag returns the tag cosign uses to attach artifacts to a manifest,
+// i.e sha256-<hex>.sig
+func cosignTag(digest v1.Hash, suffix string) string {
+	return digest.Algorithm + "-" + digest.Hex + suffix
+}
+
+// artifactKind guesses the kind of artifact from its OCI artifact type
+func artifactKind(artifactType string) string {
+	t := strings.ToLower(artifactType)
+	switch {
+	case strings.Contains(t, "spdx"), strings.Contains(t, "cyclonedx"), strings.Contains(t, "sbom"):
+		return types.ArtifactKindSBOM
+	case strings.Contains(t, "in-toto"), strings.Contains(t, "attestation"):
+		return types.ArtifactKindAttestation
+	case strings.Contains(t, "signature"), strings.Contains(t, "sigstore"), strings.Contains(t, "cosign"), strings.Contains(t, "notary"):
+		return types.ArtifactKindSignature
+	}
+	return types.ArtifactKindOther
+}
+
+// isNotFound returns whether err is a registry not found error
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
@@ -4,11 +4,21 @@ package common
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/bitnami/charts-syncer/internal/discovery"
 	"github.com/bitnami/charts-syncer/pkg/client"
 	"github.com/bitnami/charts-syncer/pkg/client/config"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/juju/errors"
 	"github.com/vmware-labs/distribution-tooling-for-helm/cmd/dt/wrap"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	dtutils "github.com/vmware-labs/distribution-tooling-for-helm/pkg/utils"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/klog"
 )
 
 // Source allows to operate a chart source.
@@ -52,7 +62,18 @@ func (t *Source) Wrap(tgz, destWrap string, opts ...config.Option) (string, erro
 
 	fetchArtifacts := !cfg.SkipArtifacts
 
-	outputFile, err := wrap.Chart(tgz, wrap.WithFetchArtifacts(fetchArtifacts),
+	input := tgz
+	if len(cfg.ImageDiscovery) > 0 {
+		dir, err := discoverImages(tgz, wrapWorkdir, cfg.ImageDiscovery)
+		if err != nil {
+			return "", errors.Annotatef(err, "discovering %q chart images", tgz)
+		}
+		if dir != "" {
+			input = dir
+		}
+	}
+
+	outputFile, err := wrap.Chart(input, wrap.WithFetchArtifacts(fetchArtifacts),
 		wrap.WithInsecure(t.insecure), wrap.WithTempDirectory(wrapWorkdir),
 		wrap.WithAuth(t.username, t.password),
 		wrap.WithPlatforms(cfg.ContainerPlatforms),
@@ -64,3 +85,62 @@ func (t *Source) Wrap(tgz, destWrap string, opts ...config.Option) (string, erro
 	}
 	return outputFile, nil
 }
+
+// discoverImages looks for the images of a chart not listing them in its
+// "images" annotation, so they are wrapped and relocated along with the chart.
+// The chart is extracted into workdir and annotated with the images found, and
+// the path to the annotated chart is returned. An empty path is returned if
+// the chart does not need to be annotated.
+func discoverImages(tgz, workdir string, methods []api.ImageDiscovery) (string, error) {
+	c, err := loader.Load(tgz)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if discovery.IsAnnotated(c) {
+		return "", nil
+	}
+	images, err := discovery.Images(c, methods...)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	id := fmt.Sprintf("%s-%s", c.Name(), c.Metadata.Version)
+	if len(images) == 0 {
+		klog.Warningf("%q chart has no images annotation and no images were discovered", id)
+		return "", nil
+	}
+	klog.Infof("Discovered %d images in %q chart: %s", len(images), id, strings.Join(images, ", "))
+
+	annotation, err := discovery.Annotation(images)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	dir := filepath.Join(workdir, "discovery")
+	// Charts are compressed with a "<name>" root folder
+	if err := dtutils.Untar(tgz, dir, dtutils.TarConfig{StripComponents: 1}); err != nil {
+		return "", errors.Annotatef(err, "extracting %q chart", tgz)
+	}
+	if err := chartwrap.SetChartAnnotations(dir, map[string]string{imagelock.DefaultAnnotationsKey: annotation}); err != nil {
+		return "", errors.Annotatef(err, "annotating %q chart", id)
+	}
+	return dir, nil
+}
+
+// ListArtifacts lists the artifacts attached to a chart. Repositories not
+// supporting artifacts have none.
+func (t *Source) ListArtifacts(name, version string) ([]*types.Artifact, error) {
+	r, ok := t.ChartsReader.(client.ArtifactsReader)
+	if !ok {
+		return nil, nil
+	}
+	return r.ListArtifacts(name, version)
+}
+
+// FetchProvenance fetches the provenance file of a chart. A not supported
+// error is returned if the repository does not publish provenance files.
+func (t *Source) FetchProvenance(name, version string) (string, error) {
+	r, ok := t.ChartsReader.(client.ProvenanceReader)
+	if !ok {
+		return "", errors.NotSupportedf("provenance files")
+	}
+	return r.FetchProvenance(name, version)
+}
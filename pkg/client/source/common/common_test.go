@@ -0,0 +1,37 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestDiscoverImages(t *testing.T) {
+	dir, err := discoverImages("../../../../testdata/apache-7.3.15.tgz", t.TempDir(), []api.ImageDiscovery{api.ImageDiscovery_TEMPLATES})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir == "" {
+		t.Fatal("expected the chart to be annotated")
+	}
+	c, err := loader.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err := imagelock.GetImagesFromChartAnnotations(c, imagelock.NewImagesLockConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].Name != "apache" || images[0].Image != "docker.io/bitnami/apache:2.4.43-debian-10-r25" {
+		t.Errorf("got images annotation: %+v, want apache image", images)
+	}
+
+	// Annotated charts are not changed
+	if dir, err = discoverImages(dir, t.TempDir(), []api.ImageDiscovery{api.ImageDiscovery_VALUES}); err != nil {
+		t.Fatal(err)
+	} else if dir != "" {
+		t.Errorf("got annotated chart in %q, want none", dir)
+	}
+}
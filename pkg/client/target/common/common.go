@@ -6,11 +6,14 @@ import (
 	"regexp"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/sign"
 	"github.com/bitnami/charts-syncer/pkg/client"
 	"github.com/bitnami/charts-syncer/pkg/client/config"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
 	"github.com/juju/errors"
 	"github.com/vmware-labs/distribution-tooling-for-helm/cmd/dt/unwrap"
 	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/klog"
 )
 
 var (
@@ -25,6 +28,7 @@ type Target struct {
 	containersURL      string
 	containersUsername string
 	containersPassword string
+	imageMappings      []*api.ImageMapping
 	insecure           bool
 	usePlainHTTP       bool
 }
@@ -40,6 +44,7 @@ func New(target *api.Target, chartWriter client.ChartsReaderWriter, insecure boo
 	}
 	if containers != nil {
 		s.containersURL = containers.GetUrl()
+		s.imageMappings = containers.GetImageMappings()
 		if containers.GetAuth() != nil {
 			s.containersUsername = containers.GetAuth().GetUsername()
 			s.containersPassword = containers.GetAuth().GetPassword()
@@ -72,6 +77,11 @@ func (t *Target) Unwrap(file string, _ *chart.Metadata, opts ...config.Option) e
 
 	defer os.RemoveAll(wrapWorkdir)
 
+	// unwrap.Chart relocates all the images under the same prefix
+	if len(t.imageMappings) > 0 {
+		return errors.Trace(t.unwrapWithMappings(file, wrapWorkdir, cfg.Logger))
+	}
+
 	if _, err := unwrap.Chart(file, t.getContainersUploadURL(), t.GetUploadURL(), unwrap.WithSayYes(true),
 		unwrap.WithTempDirectory(wrapWorkdir),
 		unwrap.WithUsePlainHTTP(t.usePlainHTTP),
@@ -83,3 +93,35 @@ func (t *Target) Unwrap(file string, _ *chart.Metadata, opts ...config.Option) e
 	}
 	return nil
 }
+
+// PushArtifacts attaches artifacts to a chart. Repositories not supporting
+// artifacts ignore them.
+func (t *Target) PushArtifacts(name, version string, artifacts []*types.Artifact) error {
+	w, ok := t.ChartsReaderWriter.(client.ArtifactsWriter)
+	if !ok {
+		klog.V(3).Infof("Skipping %d artifacts of %s:%s as %q does not support them", len(artifacts), name, version, t.GetUploadURL())
+		return nil
+	}
+	return w.PushArtifacts(name, version, artifacts)
+}
+
+// PushProvenance adds a provenance file to a chart. Repositories not
+// supporting provenance files ignore it.
+func (t *Target) PushProvenance(name, version, provPath string) error {
+	w, ok := t.ChartsReaderWriter.(client.ProvenanceWriter)
+	if !ok {
+		klog.V(3).Infof("Skipping %s:%s provenance file as %q does not support it", name, version, t.GetUploadURL())
+		return nil
+	}
+	return w.PushProvenance(name, version, provPath)
+}
+
+// SignChart signs a chart. An error is returned if the repository does not
+// support signatures.
+func (t *Target) SignChart(name, version string, signer *sign.Signer) error {
+	s, ok := t.ChartsReaderWriter.(client.ChartSigner)
+	if !ok {
+		return errors.NotSupportedf("signing charts in %q", t.GetUploadURL())
+	}
+	return s.SignChart(name, version, signer)
+}
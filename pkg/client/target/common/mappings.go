@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitnami/charts-syncer/internal/relocator"
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/cmd/dt/push"
+	"github.com/vmware-labs/distribution-tooling-for-helm/cmd/dt/verify"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/artifacts"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/chartutils"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log/silent"
+	dtutils "github.com/vmware-labs/distribution-tooling-for-helm/pkg/utils"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+)
+
+// unwrapWithMappings unwraps a chart relocating its images with the image
+// mapping rules of the target. It follows the same steps as unwrap.Chart:
+// relocate the chart, push the images, verify the Images.lock file and push
+// the chart.
+func (t *Target) unwrapWithMappings(file, workdir string, l log.SectionLogger) error {
+	ctx := context.Background()
+
+	dir := filepath.Join(workdir, "wrap")
+	if err := dtutils.Untar(file, dir, dtutils.TarConfig{StripComponents: 1}); err != nil {
+		return errors.Annotatef(err, "extracting %q wrap", file)
+	}
+	wrap, err := wrapping.Load(dir)
+	if err != nil {
+		return errors.Annotatef(err, "loading %q wrap", file)
+	}
+
+	r := relocator.New(t.getContainersUploadURL(), t.imageMappings...)
+	if err := l.ExecuteStep(fmt.Sprintf("Relocating %q with the image mappings", wrap.Chart().Name()), func() error {
+		return r.RelocateChartDir(wrap.ChartDir(), imagelock.DefaultAnnotationsKey)
+	}); err != nil {
+		return errors.Annotatef(err, "relocating %q chart", wrap.Chart().Name())
+	}
+
+	lock, err := wrap.GetImagesLock()
+	if err != nil {
+		return errors.Annotatef(err, "loading Images.lock")
+	}
+	if len(lock.Images) > 0 {
+		if err := l.Section("Pushing Images", func(subLog log.SectionLogger) error {
+			return push.ChartImages(wrap, wrap.ImagesDir(),
+				chartutils.WithLog(silent.NewLogger()),
+				chartutils.WithContext(ctx),
+				chartutils.WithArtifactsDir(wrap.ImageArtifactsDir()),
+				chartutils.WithProgressBar(subLog.ProgressBar()),
+				chartutils.WithInsecureMode(t.insecure),
+				chartutils.WithAuth(t.containersUsername, t.containersPassword),
+			)
+		}); err != nil {
+			return errors.Annotatef(err, "pushing images")
+		}
+		if err := l.ExecuteStep("Verifying Images.lock", func() error {
+			return verify.Lock(wrap.ChartDir(), wrap.LockFilePath(), verify.Config{
+				Insecure: t.insecure, AnnotationsKey: imagelock.DefaultAnnotationsKey,
+				Auth: verify.Auth{Username: t.containersUsername, Password: t.containersPassword},
+			})
+		}); err != nil {
+			return errors.Annotatef(err, "verifying Images.lock")
+		}
+	}
+
+	return errors.Trace(l.ExecuteStep(fmt.Sprintf("Pushing Helm chart to %q", t.GetUploadURL()), func() error {
+		return t.pushChart(ctx, wrap, workdir)
+	}))
+}
+
+// pushChart pushes the chart of a wrap, including its metadata artifacts, to
+// the target
+func (t *Target) pushChart(ctx context.Context, wrap wrapping.Wrap, workdir string) error {
+	chart := wrap.Chart()
+	tgz := filepath.Join(workdir, fmt.Sprintf("%s.tgz", chart.Name()))
+	if err := dtutils.Tar(chart.RootDir(), tgz, dtutils.TarConfig{Prefix: chart.Name()}); err != nil {
+		return errors.Annotatef(err, "compressing %q chart", chart.Name())
+	}
+
+	pushURL := fmt.Sprintf("oci://%s", schemeRE.ReplaceAllString(t.GetUploadURL(), ""))
+	if err := artifacts.PushChart(tgz, pushURL,
+		artifacts.WithInsecure(t.insecure), artifacts.WithPlainHTTP(t.usePlainHTTP),
+		artifacts.WithRegistryAuth(t.username, t.password),
+		artifacts.WithTempDir(workdir),
+	); err != nil {
+		return errors.Trace(err)
+	}
+
+	metadataDir := filepath.Join(chart.RootDir(), artifacts.HelmChartArtifactMetadataDir)
+	if !dtutils.FileExists(metadataDir) {
+		return nil
+	}
+	return errors.Trace(artifacts.PushChartMetadata(ctx, fmt.Sprintf("%s/%s:%s", pushURL, chart.Name(), chart.Version()), metadataDir,
+		artifacts.WithAuth(t.username, t.password)))
+}
@@ -3,12 +3,51 @@ package types
 
 import (
 	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 // ChartDetails contains details of a chart
 type ChartDetails struct {
 	PublishedAt time.Time
 	Digest      string
+	// Deprecated is whether the chart version is marked as deprecated
+	Deprecated bool
+	// Annotations are the annotations of the chart version, if the
+	// repository index records them
+	Annotations map[string]string
+}
+
+// Kinds of artifacts attached to a chart
+const (
+	ArtifactKindSignature   = "signature"
+	ArtifactKindSBOM        = "sbom"
+	ArtifactKindAttestation = "attestation"
+	ArtifactKindProvenance  = "provenance"
+	ArtifactKindOther       = "other"
+)
+
+// Artifact is an artifact attached to a chart, like a signature, an SBOM or
+// an attestation
+type Artifact struct {
+	// Kind is the kind of artifact: signature, sbom, attestation or other
+	Kind string `json:"kind"`
+	// Digest is the digest of the artifact manifest in the source
+	Digest string `json:"digest"`
+	// Subject is the digest of the chart manifest the artifact is attached to
+	// in the source
+	Subject string `json:"-"`
+	// ChartDigest is the digest of the chart content layer of the subject
+	// manifest in the source
+	ChartDigest string `json:"-"`
+	// ArtifactType is the OCI artifact type, if any
+	ArtifactType string `json:"artifactType,omitempty"`
+	// TagSuffix is the suffix of the tag of artifacts attached with the
+	// cosign tag schema, i.e ".sig" in "sha256-<digest>.sig". It is empty
+	// for artifacts attached with the OCI referrers API.
+	TagSuffix string `json:"tagSuffix,omitempty"`
+	// Image is the artifact content
+	Image v1.Image `json:"-"`
 }
 
 // ClientOpts allows to configure a client
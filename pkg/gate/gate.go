@@ -0,0 +1,56 @@
+// Package gate defines the checks run over the wrapped charts before
+// publishing them into the targets
+package gate
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Decision is the outcome of a gate
+type Decision int
+
+const (
+	// Allow publishes the chart
+	Allow Decision = iota
+	// Warn publishes the chart, warning about it in the sync report
+	Warn
+	// Deny does not publish the chart
+	Deny
+)
+
+// String returns the name of the decision
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Warn:
+		return "warn"
+	case Deny:
+		return "deny"
+	}
+	return "unknown"
+}
+
+// Request describes a wrapped chart about to be published
+type Request struct {
+	// Bundle is the path to the wrapped chart
+	Bundle string `json:"bundle"`
+	// Chart is the metadata of the chart
+	Chart *chart.Metadata `json:"chart"`
+	// Images are the container images of the chart
+	Images []string `json:"images"`
+}
+
+// Result is the decision of a gate about a chart
+type Result struct {
+	Decision Decision
+	// Reason explains why the chart was not allowed
+	Reason string
+}
+
+// Gate checks a wrapped chart before it is published
+type Gate interface {
+	Check(ctx context.Context, req *Request) (*Result, error)
+}
@@ -0,0 +1,137 @@
+package gate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"k8s.io/klog"
+
+	"github.com/bitnami/charts-syncer/api"
+)
+
+// maxReportedVulnerabilities is the maximum number of vulnerabilities listed
+// in the reason of a failed scan
+const maxReportedVulnerabilities = 5
+
+// severities ranks the vulnerability severities reported by scanners
+var severities = map[string]int{
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// Scanner is a gate running an external command to scan the images of the
+// charts, like a local scanner reading an offline vulnerability database
+type Scanner struct {
+	command   []string
+	threshold api.Severity
+	policy    api.ScanPolicy
+}
+
+// NewScanner creates a Scanner from an api.Scan object
+func NewScanner(cfg *api.Scan) *Scanner {
+	return &Scanner{
+		command:   cfg.GetCommand(),
+		threshold: cfg.GetSeverity(),
+		policy:    cfg.GetPolicy(),
+	}
+}
+
+// vulnerability is a vulnerability found by the scanner
+type vulnerability struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+}
+
+// scanReport is the output of the scanner. Besides its own format, listing
+// the vulnerabilities found, the JSON reports of trivy and grype are
+// supported so they can be used without wrapping their output.
+type scanReport struct {
+	Vulnerabilities []vulnerability `json:"vulnerabilities"`
+	// trivy
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+	// grype
+	Matches []struct {
+		Vulnerability vulnerability `json:"vulnerability"`
+	} `json:"matches"`
+}
+
+// vulnerabilities returns all the vulnerabilities of the report
+func (r *scanReport) vulnerabilities() []vulnerability {
+	vulns := append([]vulnerability{}, r.Vulnerabilities...)
+	for _, res := range r.Results {
+		for _, v := range res.Vulnerabilities {
+			vulns = append(vulns, vulnerability{ID: v.VulnerabilityID, Severity: v.Severity})
+		}
+	}
+	for _, m := range r.Matches {
+		vulns = append(vulns, m.Vulnerability)
+	}
+	return vulns
+}
+
+// Check runs the scanner over a wrapped chart. The chart fails the scan if
+// the scanner finds vulnerabilities with the threshold severity or higher.
+func (s *Scanner) Check(ctx context.Context, req *Request) (*Result, error) {
+	if len(s.command) == 0 {
+		return nil, errors.New("missing scanner command")
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...) // #nosec G204
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	klog.V(4).Infof("Scanning %s-%s chart with %q", req.Chart.Name, req.Chart.Version, strings.Join(s.command, " "))
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Annotatef(err, "running scanner: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var report scanReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, errors.Annotatef(err, "parsing scanner output")
+	}
+
+	threshold := severities[api.Severity_name[int32(s.threshold)]]
+	var ids []string
+	for _, v := range report.vulnerabilities() {
+		if severities[strings.ToUpper(v.Severity)] >= threshold {
+			ids = append(ids, v.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return &Result{Decision: Allow}, nil
+	}
+
+	sort.Strings(ids)
+	noun := "vulnerabilities"
+	if len(ids) == 1 {
+		noun = "vulnerability"
+	}
+	reason := fmt.Sprintf("%d %s with %s or higher severity", len(ids), noun, s.threshold)
+	if len(ids) > maxReportedVulnerabilities {
+		reason = fmt.Sprintf("%s: %s, ...", reason, strings.Join(ids[:maxReportedVulnerabilities], ", "))
+	} else {
+		reason = fmt.Sprintf("%s: %s", reason, strings.Join(ids, ", "))
+	}
+	if s.policy == api.ScanPolicy_WARN {
+		return &Result{Decision: Warn, Reason: reason}, nil
+	}
+	return &Result{Decision: Deny, Reason: reason}, nil
+}
@@ -0,0 +1,96 @@
+package gate_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/gate"
+)
+
+func TestScannerCheck(t *testing.T) {
+	tests := []struct {
+		desc   string
+		output string
+		scan   *api.Scan
+		want   *gate.Result
+	}{
+		{
+			desc:   "no vulnerabilities",
+			output: `{"vulnerabilities":[]}`,
+			scan:   &api.Scan{Severity: api.Severity_HIGH},
+			want:   &gate.Result{Decision: gate.Allow},
+		},
+		{
+			desc:   "vulnerabilities below the threshold",
+			output: `{"vulnerabilities":[{"id":"CVE-2024-0001","severity":"MEDIUM"}]}`,
+			scan:   &api.Scan{Severity: api.Severity_HIGH},
+			want:   &gate.Result{Decision: gate.Allow},
+		},
+		{
+			desc:   "trivy report denied",
+			output: `{"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0002","Severity":"CRITICAL"},{"VulnerabilityID":"CVE-2024-0001","Severity":"HIGH"}]}]}`,
+			scan:   &api.Scan{Severity: api.Severity_HIGH},
+			want:   &gate.Result{Decision: gate.Deny, Reason: "2 vulnerabilities with HIGH or higher severity: CVE-2024-0001, CVE-2024-0002"},
+		},
+		{
+			desc:   "grype report with warnings",
+			output: `{"matches":[{"vulnerability":{"id":"CVE-2024-0003","severity":"Critical"}}]}`,
+			scan:   &api.Scan{Policy: api.ScanPolicy_WARN},
+			want:   &gate.Result{Decision: gate.Warn, Reason: "1 vulnerability with CRITICAL or higher severity: CVE-2024-0003"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.scan.Command = []string{"sh", "-c", "cat > /dev/null; echo '" + tc.output + "'"}
+			got, err := gate.NewScanner(tc.scan).Check(context.Background(), &gate.Request{
+				Bundle: "apache-7.3.15.wrap.tgz",
+				Chart:  &chart.Metadata{Name: "apache", Version: "7.3.15"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got: %+v, want: %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScannerCheckRequest(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "request.json")
+	s := gate.NewScanner(&api.Scan{Command: []string{"sh", "-c", `cat > "$0"; echo '{}'`, in}})
+	req := &gate.Request{
+		Bundle: "apache-7.3.15.wrap.tgz",
+		Chart:  &chart.Metadata{Name: "apache", Version: "7.3.15"},
+		Images: []string{"docker.io/bitnami/apache:2.4.41"},
+	}
+	if _, err := s.Check(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &gate.Request{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("got request: %+v, want: %+v", got, req)
+	}
+}
+
+func TestScannerCheckFailure(t *testing.T) {
+	s := gate.NewScanner(&api.Scan{Command: []string{"sh", "-c", "echo 'database not found' >&2; exit 1"}})
+	if _, err := s.Check(context.Background(), &gate.Request{Chart: &chart.Metadata{Name: "apache", Version: "7.3.15"}}); err == nil {
+		t.Errorf("expected error when the scanner fails")
+	}
+}
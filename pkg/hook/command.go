@@ -0,0 +1,68 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+	"k8s.io/klog"
+
+	"github.com/bitnami/charts-syncer/api"
+)
+
+// Command is a hook running an external command. The command gets the event
+// as JSON in its standard input, and can print a Result as JSON.
+type Command struct {
+	command []string
+	stages  map[Stage]bool
+}
+
+// NewCommand creates a Command from an api.Hook object
+func NewCommand(cfg *api.Hook) *Command {
+	c := &Command{command: cfg.GetCommand()}
+	for _, st := range cfg.GetStages() {
+		if c.stages == nil {
+			c.stages = make(map[Stage]bool)
+		}
+		c.stages[Stage(st.String())] = true
+	}
+	return c
+}
+
+// Run runs the command, if it is configured for the event stage
+func (c *Command) Run(ctx context.Context, ev *Event) (*Result, error) {
+	if c.stages != nil && !c.stages[ev.Stage] {
+		return nil, nil
+	}
+	if len(c.command) == 0 {
+		return nil, errors.New("missing hook command")
+	}
+	in, err := json.Marshal(ev)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...) // #nosec G204
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	klog.V(4).Infof("Running %q hook %q", ev.Stage, strings.Join(c.command, " "))
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Annotatef(err, "running %q hook: %s", strings.Join(c.command, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	// Hooks not printing anything continue the sync
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		return nil, nil
+	}
+	res := &Result{}
+	if err := json.Unmarshal(out, res); err != nil {
+		return nil, errors.Annotatef(err, "parsing %q hook output", strings.Join(c.command, " "))
+	}
+	return res, nil
+}
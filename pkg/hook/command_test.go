@@ -0,0 +1,92 @@
+package hook_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/hook"
+)
+
+func TestCommandRun(t *testing.T) {
+	ev := &hook.Event{
+		Stage: hook.AfterWrap,
+		Chart: &hook.Chart{Name: "apache", Version: "7.3.15", TargetName: "apache", Bundle: "apache-7.3.15.wrap.tgz"},
+	}
+	tests := []struct {
+		desc   string
+		script string
+		stages []api.HookStage
+		want   *hook.Result
+	}{
+		{
+			desc:   "no output",
+			script: "cat > /dev/null",
+		},
+		{
+			desc:   "veto",
+			script: `cat > /dev/null; echo '{"veto": true, "reason": "change window closed"}'`,
+			want:   &hook.Result{Veto: true, Reason: "change window closed"},
+		},
+		{
+			desc:   "annotations",
+			script: `cat > /dev/null; echo '{"annotations": {"ticket": "CHG-1234"}}'`,
+			stages: []api.HookStage{api.HookStage_AFTER_WRAP},
+			want:   &hook.Result{Annotations: map[string]string{"ticket": "CHG-1234"}},
+		},
+		{
+			desc:   "other stages",
+			script: `cat > /dev/null; echo '{"veto": true}'`,
+			stages: []api.HookStage{api.HookStage_BEFORE_INDEX, api.HookStage_END_OF_RUN},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			h := hook.NewCommand(&api.Hook{Command: []string{"sh", "-c", tc.script}, Stages: tc.stages})
+			got, err := h.Run(context.Background(), ev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got: %+v, want: %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommandRunEvent(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "event.json")
+	h := hook.NewCommand(&api.Hook{Command: []string{"sh", "-c", `cat > "$0"`, in}})
+	ev := &hook.Event{
+		Stage:  hook.AfterUnwrap,
+		DryRun: true,
+		Chart:  &hook.Chart{Name: "apache", Version: "7.3.15", TargetName: "web-apache", Annotations: map[string]string{"ticket": "CHG-1234"}},
+		Target: "http://localhost:9090/charts",
+	}
+	if _, err := h.Run(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &hook.Event{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ev) {
+		t.Errorf("got event: %+v, want: %+v", got, ev)
+	}
+}
+
+func TestCommandRunFailure(t *testing.T) {
+	h := hook.NewCommand(&api.Hook{Command: []string{"sh", "-c", "echo 'ticketing system unavailable' >&2; exit 1"}})
+	if _, err := h.Run(context.Background(), &hook.Event{Stage: hook.BeforeIndex}); err == nil {
+		t.Errorf("expected error when the hook fails")
+	}
+}
@@ -0,0 +1,90 @@
+// Package hook defines the hooks run at different stages of a sync, to
+// integrate it with external systems like ticketing or change management
+package hook
+
+import (
+	"context"
+)
+
+// Stage is a stage of the sync where hooks are run
+type Stage string
+
+// Stages of the sync, named after the api.HookStage values
+const (
+	// BeforeIndex runs before indexing the charts to sync
+	BeforeIndex Stage = "BEFORE_INDEX"
+	// AfterFetch runs after fetching a chart from the source
+	AfterFetch Stage = "AFTER_FETCH"
+	// AfterWrap runs after wrapping a chart, before publishing it
+	AfterWrap Stage = "AFTER_WRAP"
+	// AfterUnwrap runs after publishing a chart into a target
+	AfterUnwrap Stage = "AFTER_UNWRAP"
+	// EndOfRun runs at the end of the sync
+	EndOfRun Stage = "END_OF_RUN"
+)
+
+// CanVeto returns whether the hooks can veto the chart or the sync at the
+// stage. Charts are already published in the AfterUnwrap and EndOfRun stages,
+// so the hooks can only annotate them.
+func (s Stage) CanVeto() bool {
+	return s != AfterUnwrap && s != EndOfRun
+}
+
+// Chart describes the chart being synced
+type Chart struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// TargetName is the name of the chart in the targets
+	TargetName string `json:"targetName"`
+	// TargetVersion is the version of the chart in the targets
+	TargetVersion string `json:"targetVersion"`
+	// Source is the source repository of the chart
+	Source string `json:"source"`
+	// Path is the path to the fetched chart package
+	Path string `json:"path,omitempty"`
+	// Bundle is the path to the wrapped chart, from the AfterWrap stage
+	Bundle string `json:"bundle,omitempty"`
+	// Annotations are the annotations added by previous hooks
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Event is the input of a hook
+type Event struct {
+	Stage  Stage `json:"stage"`
+	DryRun bool  `json:"dryRun"`
+	// Charts are the names of the charts requested, in the BeforeIndex stage.
+	// All the charts are synced if empty.
+	Charts []string `json:"charts,omitempty"`
+	// Chart is the chart being synced, in the chart stages
+	Chart *Chart `json:"chart,omitempty"`
+	// Target is the target the chart was published into, in the AfterUnwrap
+	// stage
+	Target string `json:"target,omitempty"`
+	// Report is the report of the sync, in the EndOfRun stage
+	Report interface{} `json:"report,omitempty"`
+}
+
+// Result is the output of a hook. A nil result continues the sync.
+type Result struct {
+	// Veto stops syncing the chart, or the whole sync in the BeforeIndex
+	// stage. It is ignored in the stages the hooks cannot veto.
+	Veto bool `json:"veto"`
+	// Reason explains the veto
+	Reason string `json:"reason"`
+	// Annotations are added to the chart, or to the sync report in the
+	// BeforeIndex and EndOfRun stages
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Hook is run at the different stages of a sync
+type Hook interface {
+	Run(ctx context.Context, ev *Event) (*Result, error)
+}
+
+// Func is a function implementing a Hook
+type Func func(ctx context.Context, ev *Event) (*Result, error)
+
+// Run runs the function
+func (f Func) Run(ctx context.Context, ev *Event) (*Result, error) {
+	return f(ctx, ev)
+}
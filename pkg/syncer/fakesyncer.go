@@ -7,8 +7,11 @@ import (
 	"testing"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/client"
 	localSource "github.com/bitnami/charts-syncer/pkg/client/source/local"
 	localTarget "github.com/bitnami/charts-syncer/pkg/client/target/local"
+	"github.com/bitnami/charts-syncer/pkg/gate"
+	"github.com/bitnami/charts-syncer/pkg/hook"
 
 	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log/silent"
 )
@@ -16,7 +19,20 @@ import (
 // FakeSyncerOpts allows to configure a Fake syncer.
 type FakeSyncerOpts struct {
 	Destination string
-	skipCharts  []string
+	// Workdir is the directory where the charts are wrapped outside the
+	// allowed windows
+	Workdir string
+	// ExtraDestinations are additional directories to sync into
+	ExtraDestinations []string
+	// ExtraSources are additional directories to sync from
+	ExtraSources   []*api.Source
+	conflictPolicy api.ConflictPolicy
+	chartMappings  []*api.ChartMapping
+	skipCharts     []string
+	gates          []gate.Gate
+	hooks          []hook.Hook
+	transform      *api.Transform
+	allowedWindows []*api.AllowedWindow
 }
 
 // FakeSyncerOption is an option value used to create a new fake syncer instance.
@@ -29,6 +45,47 @@ func WithFakeSyncerDestination(dir string) FakeSyncerOption {
 	}
 }
 
+// WithFakeSyncerWorkdir configures the working directory
+func WithFakeSyncerWorkdir(dir string) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.Workdir = dir
+	}
+}
+
+// WithFakeSyncerExtraDestination configures an additional destination directory
+func WithFakeSyncerExtraDestination(dir string) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.ExtraDestinations = append(s.ExtraDestinations, dir)
+	}
+}
+
+// WithFakeSyncerExtraSource configures an additional source directory. The
+// prefix is used to rename conflicting charts.
+func WithFakeSyncerExtraSource(dir, prefix string) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.ExtraSources = append(s.ExtraSources, &api.Source{
+			Repo:   &api.Repo{Kind: api.Kind_LOCAL, Path: dir},
+			Prefix: prefix,
+		})
+	}
+}
+
+// WithFakeConflictPolicy configures how to handle charts published by several
+// sources.
+func WithFakeConflictPolicy(p api.ConflictPolicy) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.conflictPolicy = p
+	}
+}
+
+// WithFakeChartMappings configures the rules to rename charts and choose where
+// they are stored in the destinations.
+func WithFakeChartMappings(mappings ...*api.ChartMapping) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.chartMappings = append(s.chartMappings, mappings...)
+	}
+}
+
 // WithFakeSkipCharts configures the syncer to skip an explicit list of chart names
 // from the source chart repos.
 func WithFakeSkipCharts(charts []string) FakeSyncerOption {
@@ -37,6 +94,37 @@ func WithFakeSkipCharts(charts []string) FakeSyncerOption {
 	}
 }
 
+// WithFakeGates configures the gates checking the wrapped charts before
+// publishing them.
+func WithFakeGates(gates ...gate.Gate) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.gates = append(s.gates, gates...)
+	}
+}
+
+// WithFakeHooks configures the hooks run at the different stages of the sync.
+func WithFakeHooks(hooks ...hook.Hook) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
+// WithFakeTransform configures the changes applied to the charts before
+// publishing them.
+func WithFakeTransform(t *api.Transform) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.transform = t
+	}
+}
+
+// WithFakeAllowedWindows configures the windows when the charts can be pushed
+// to the destinations.
+func WithFakeAllowedWindows(windows ...*api.AllowedWindow) FakeSyncerOption {
+	return func(s *FakeSyncerOpts) {
+		s.allowedWindows = append(s.allowedWindows, windows...)
+	}
+}
+
 // NewFake returns a fake Syncer
 func NewFake(t *testing.T, opts ...FakeSyncerOption) *Syncer {
 	sopts := &FakeSyncerOpts{}
@@ -58,6 +146,14 @@ func NewFake(t *testing.T, opts ...FakeSyncerOption) *Syncer {
 		t.Cleanup(func() { _ = os.RemoveAll(dstTmp) })
 		sopts.Destination = dstTmp
 	}
+	if sopts.Workdir == "" {
+		workdirTmp, err := os.MkdirTemp("", "charts-syncer-tests-workdir-fake")
+		if err != nil {
+			t.Fatalf("error creating temporary folder: %v", err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(workdirTmp) })
+		sopts.Workdir = workdirTmp
+	}
 
 	// Copy all testdata tgz files to the source temporary folder
 	// We are not adding charts in the entries only to avoid specifying
@@ -78,23 +174,42 @@ func NewFake(t *testing.T, opts ...FakeSyncerOption) *Syncer {
 		}
 	}
 
-	srcCli, err := localSource.New(srcTmp)
-	if err != nil {
-		t.Fatalf("error creating source client: %v", err)
+	sources := []*api.Source{{Repo: &api.Repo{Kind: api.Kind_LOCAL, Path: srcTmp}}}
+	sources = append(sources, sopts.ExtraSources...)
+	var srcClis []client.ChartsWrapper
+	for _, src := range sources {
+		srcCli, err := localSource.New(src.GetRepo().GetPath())
+		if err != nil {
+			t.Fatalf("error creating source client: %v", err)
+		}
+		srcClis = append(srcClis, srcCli)
 	}
-	dstCli, err := localTarget.New(sopts.Destination)
-	if err != nil {
-		t.Fatalf("error creating target client: %v", err)
+	var targets []*api.Target
+	var dstClis []client.ChartsUnwrapper
+	for _, dir := range append([]string{sopts.Destination}, sopts.ExtraDestinations...) {
+		dstCli, err := localTarget.New(dir)
+		if err != nil {
+			t.Fatalf("error creating target client: %v", err)
+		}
+		targets = append(targets, &api.Target{Repo: &api.Repo{Kind: api.Kind_LOCAL, Path: dir}})
+		dstClis = append(dstClis, dstCli)
 	}
 
 	return &Syncer{
-		source: &api.Source{},
-		target: &api.Target{},
+		sources: sources,
+		targets: targets,
 		cli: &Clients{
-			src: srcCli,
-			dst: dstCli,
+			src: srcClis,
+			dst: dstClis,
 		},
-		skipCharts: sopts.skipCharts,
-		logger:     silent.NewSectionLogger(),
+		skipCharts:     sopts.skipCharts,
+		conflictPolicy: sopts.conflictPolicy,
+		chartMappings:  sopts.chartMappings,
+		gates:          sopts.gates,
+		hooks:          sopts.hooks,
+		transform:      sopts.transform,
+		allowedWindows: sopts.allowedWindows,
+		workdir:        sopts.Workdir,
+		logger:         silent.NewSectionLogger(),
 	}
 }
@@ -0,0 +1,66 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/bitnami/charts-syncer/pkg/gate"
+	"github.com/juju/errors"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	"k8s.io/klog"
+)
+
+// ErrChartDenied is returned when a gate does not allow publishing a chart
+var ErrChartDenied = errors.New("chart denied")
+
+// WithGates configures the syncer to check the wrapped charts with gates
+// before publishing them
+func WithGates(gates ...gate.Gate) Option {
+	return func(s *Syncer) {
+		s.gates = append(s.gates, gates...)
+	}
+}
+
+// WithScan configures the syncer to scan the images of the wrapped charts
+// before publishing them
+func WithScan(scan *api.Scan) Option {
+	return func(s *Syncer) {
+		if scan != nil {
+			s.gates = append(s.gates, gate.NewScanner(scan))
+		}
+	}
+}
+
+// checkGates checks a wrapped chart with the gates. Charts denied by any gate
+// are not published, and warnings are added to the chart.
+func (s *Syncer) checkGates(ch *Chart, bundle string, metadata *helmchart.Metadata) error {
+	if len(s.gates) == 0 {
+		return nil
+	}
+	req := &gate.Request{Bundle: bundle, Chart: metadata, Images: []string{}}
+	lock, err := chartwrap.ReadImagesLock(bundle)
+	if err != nil && !errors.Is(err, errors.NotFound) {
+		return errors.Trace(err)
+	}
+	if lock != nil {
+		for _, img := range lock.Images.Dedup() {
+			req.Images = append(req.Images, img.Image)
+		}
+	}
+
+	for _, g := range s.gates {
+		res, err := g.Check(context.Background(), req)
+		if err != nil {
+			return errors.Annotatef(err, "checking %q chart", ch.id())
+		}
+		switch res.Decision {
+		case gate.Deny:
+			return errors.Annotatef(ErrChartDenied, "%q: %s", ch.id(), res.Reason)
+		case gate.Warn:
+			klog.Warningf("%q chart: %s", ch.id(), res.Reason)
+			ch.Warnings = append(ch.Warnings, res.Reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/hook"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// ErrVetoed is returned when a hook vetoes a chart or the sync
+var ErrVetoed = errors.New("vetoed by hook")
+
+// WithHooks configures the hooks run at the different stages of the sync
+func WithHooks(hooks ...hook.Hook) Option {
+	return func(s *Syncer) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
+// WithCommandHooks configures the commands run as hooks at the different
+// stages of the sync
+func WithCommandHooks(hooks ...*api.Hook) Option {
+	return func(s *Syncer) {
+		for _, h := range hooks {
+			s.hooks = append(s.hooks, hook.NewCommand(h))
+		}
+	}
+}
+
+// runHooks runs the hooks for an event, and returns the annotations they
+// added. An error is returned if any hook vetoes the event, in the stages
+// that can be vetoed.
+func (s *Syncer) runHooks(ev *hook.Event) (map[string]string, error) {
+	ev.DryRun = s.dryRun
+	var annotations map[string]string
+	for _, h := range s.hooks {
+		res, err := h.Run(context.Background(), ev)
+		if err != nil {
+			return nil, errors.Annotatef(err, "running %s hook", ev.Stage)
+		}
+		if res == nil {
+			continue
+		}
+		if res.Veto && !ev.Stage.CanVeto() {
+			klog.Warningf("Ignoring the veto of a %s hook (%s): charts cannot be vetoed once published", ev.Stage, res.Reason)
+		} else if res.Veto {
+			return nil, errors.Annotatef(ErrVetoed, "%s: %s", ev.Stage, res.Reason)
+		}
+		for k, v := range res.Annotations {
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[k] = v
+		}
+	}
+	return annotations, nil
+}
+
+// runChartHooks runs the hooks for an event about a chart, adding the
+// annotations to it
+func (s *Syncer) runChartHooks(stage hook.Stage, ch *Chart, bundle, target string) error {
+	if len(s.hooks) == 0 {
+		return nil
+	}
+	annotations, err := s.runHooks(&hook.Event{
+		Stage: stage,
+		Chart: &hook.Chart{
+			Name:          ch.Name,
+			Version:       ch.Version,
+			TargetName:    ch.TargetName,
+			TargetVersion: ch.TargetVersion,
+			Source:        s.sourceID(ch.Source),
+			Path:          ch.TgzPath,
+			Bundle:        bundle,
+			Annotations:   ch.Annotations,
+		},
+		Target: target,
+	})
+	if err != nil {
+		return errors.Annotatef(err, "%q chart", ch.id())
+	}
+	for k, v := range annotations {
+		if ch.Annotations == nil {
+			ch.Annotations = make(map[string]string)
+		}
+		ch.Annotations[k] = v
+	}
+	return nil
+}
+
+// runEndOfRunHooks runs the hooks at the end of the sync, adding the
+// annotations to the report
+func (s *Syncer) runEndOfRunHooks() error {
+	if len(s.hooks) == 0 {
+		return nil
+	}
+	annotations, err := s.runHooks(&hook.Event{Stage: hook.EndOfRun, Report: s.Report()})
+	s.annotateReport(annotations)
+	return errors.Trace(err)
+}
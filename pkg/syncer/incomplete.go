@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// incompletePath returns the path of the file marking that a chart was pushed
+// to the i-th target, but the steps after pushing it, like signing it, failed
+func (s *Syncer) incompletePath(i int, ch *Chart) string {
+	key := utils.EncodeSha1(s.targetID(i) + "|" + ch.TargetPath)
+	return filepath.Join(s.workdir, "incomplete", key[:12], ch.id())
+}
+
+// markIncomplete records that a chart was pushed to the i-th target, but not
+// completely synced, so the next sync pushes it again
+func (s *Syncer) markIncomplete(i int, ch *Chart) {
+	p := s.incompletePath(i, ch)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		klog.Warningf("unable to record that %q chart was not completely synced: %v", ch.id(), err)
+		return
+	}
+	if err := os.WriteFile(p, nil, 0600); err != nil {
+		klog.Warningf("unable to record that %q chart was not completely synced: %v", ch.id(), err)
+	}
+}
+
+// clearIncomplete removes the record of a chart not completely synced to the
+// i-th target, if any
+func (s *Syncer) clearIncomplete(i int, ch *Chart) {
+	if err := os.Remove(s.incompletePath(i, ch)); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("unable to remove the record of %q chart not completely synced: %v", ch.id(), err)
+	}
+}
+
+// completed returns whether a chart found in the i-th target was completely
+// synced. Charts pushed by a sync failing to complete them, and charts not
+// signed in targets signing them, are synced again to retry the missing steps.
+func (s *Syncer) completed(i int, ch *Chart, dst client.ChartsReader) (bool, error) {
+	if _, err := os.Stat(s.incompletePath(i, ch)); err == nil {
+		klog.Infof("%q chart was not completely synced to %q. Syncing it again", ch.id(), s.targetID(i))
+		return false, nil
+	}
+	if _, ok := s.signers[i]; !ok {
+		return true, nil
+	}
+	r, ok := dst.(client.ArtifactsReader)
+	if !ok {
+		return true, nil
+	}
+	artifacts, err := r.ListArtifacts(ch.TargetName, ch.TargetVersion)
+	if err != nil {
+		return false, errors.Annotatef(err, "listing %q chart artifacts in %q", ch.id(), s.targetID(i))
+	}
+	for _, a := range artifacts {
+		if a.Kind == types.ArtifactKindSignature {
+			return true, nil
+		}
+	}
+	klog.Infof("%q chart is not signed in %q. Syncing it again", ch.id(), s.targetID(i))
+	return false, nil
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/juju/errors"
 	"k8s.io/klog"
 
+	"github.com/bitnami/charts-syncer/api"
 	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/hook"
 )
 
 // Chart describes a chart, including dependencies
@@ -18,6 +21,32 @@ type Chart struct {
 	Name    string
 	Version string
 	TgzPath string
+	// ProvPath is the path of the chart provenance file, if the source
+	// publishes one
+	ProvPath string
+	// Targets contains the indexes of the targets missing the chart
+	Targets []int
+	// Source is the index of the source publishing the chart
+	Source int
+	// TargetName is the name of the chart in the targets
+	TargetName string
+	// TargetVersion is the version of the chart in the targets
+	TargetVersion string
+	// TargetPath is the path, relative to the targets, where the chart is stored
+	TargetPath string
+	// Unverified is the reason why the chart failed verification, if it is
+	// synced anyway
+	Unverified string
+	// Warnings are the warnings of the gates checking the chart before
+	// publishing it, and of the artifacts that could not be copied with it
+	Warnings []string
+	// Annotations are the annotations added by the hooks
+	Annotations map[string]string
+}
+
+// id returns the identifier of the chart in the index
+func (c *Chart) id() string {
+	return fmt.Sprintf("%s-%s", c.TargetName, c.TargetVersion)
 }
 
 // ChartIndex is a map linking a chart reference with its Chart
@@ -59,24 +88,8 @@ func (i ChartIndex) Get(id string) *Chart {
 	return nil
 }
 
-// loadCharts loads the charts map into the index from the source repo
+// loadCharts loads the charts map into the index from the source repos
 func (s *Syncer) loadCharts(charts ...string) error {
-	if len(charts) == 0 {
-		if !s.autoDiscovery {
-			return errors.Errorf("unable to discover charts to sync")
-		}
-		srcCharts, err := s.cli.src.List()
-		if err != nil {
-			return errors.Trace(err)
-		}
-		if len(srcCharts) == 0 {
-			return errors.Errorf("not found charts to sync")
-		}
-		charts = srcCharts
-	}
-	// Sort chart names
-	sort.Strings(charts)
-
 	// Create basic layout for date and parse flag to time type
 	publishingThreshold, err := utils.GetDateThreshold(s.fromDate)
 	if err != nil {
@@ -84,49 +97,66 @@ func (s *Syncer) loadCharts(charts ...string) error {
 	}
 	klog.V(4).Infof("Publishing threshold set to %q", publishingThreshold.String())
 
-	// Iterate over charts in source index
+	// owners links the name of a chart in the targets with the index of the
+	// source publishing it
+	owners := make(map[string]int)
+	// mapped links the charts stored in the targets with their source charts
+	mapped := make(map[string]mappedChart)
+
 	var errs error
-	for _, name := range charts {
-		if shouldSkipChart(name, s.skipCharts) {
-			klog.V(3).Infof("Indexing %q charts SKIPPED...", name)
-			continue
+	for i, src := range s.cli.src {
+		names := charts
+		if len(names) == 0 {
+			if !s.autoDiscovery {
+				return errors.Errorf("unable to discover charts to sync")
+			}
+			srcCharts, err := src.List()
+			if err != nil {
+				errs = goerrors.Join(errs, errors.Trace(err))
+				continue
+			}
+			if len(srcCharts) == 0 {
+				errs = goerrors.Join(errs, errors.Errorf("not found charts to sync"))
+				continue
+			}
+			names = srcCharts
 		}
+		// Sort chart names
+		sort.Strings(names)
 
-		versions, err := s.cli.src.ListChartVersions(name)
-		if err != nil {
-			errs = goerrors.Join(errs, errors.Trace(err))
-			continue
-		}
-		if len(versions) == 0 {
-			klog.V(5).Infof("Indexing chart %q SKIPPED (no versions found)...", name)
-			continue
-		}
-		klog.V(5).Infof("Found %d versions for %q chart", len(versions), name)
-		klog.V(3).Infof("Indexing %q charts...", name)
-		if s.latestVersionOnly {
-			vs := make([]*semver.Version, len(versions))
-			for i, r := range versions {
-				v, err := semver.NewVersion(r)
-				if err != nil {
-					return errors.Trace(err)
-				}
-				vs[i] = v
+		// Iterate over charts in source index
+		for _, name := range names {
+			if shouldSkipChart(name, s.skipCharts) {
+				klog.V(3).Infof("Indexing %q charts SKIPPED...", name)
+				continue
 			}
-			sort.Sort(semver.Collection(vs))
-			// The last element of the array is the latest version
-			version := vs[len(vs)-1].String()
-			if err := s.processVersion(name, version, publishingThreshold); err != nil {
-				klog.Warningf("Failed processing %s:%s chart. The index will remain incomplete.", name, version)
+
+			versions, err := src.ListChartVersions(name)
+			if err != nil {
 				errs = goerrors.Join(errs, errors.Trace(err))
 				continue
 			}
-		} else {
-			for _, version := range versions {
-				if err := s.processVersion(name, version, publishingThreshold); err != nil {
-					klog.Warningf("Failed processing %s:%s chart. The index will remain incomplete.", name, version)
-					errs = goerrors.Join(errs, errors.Trace(err))
-					continue
-				}
+			if len(versions) == 0 {
+				klog.V(5).Infof("Indexing chart %q SKIPPED (no versions found)...", name)
+				continue
+			}
+
+			targetName, err := s.resolveConflict(owners, i, name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if targetName == "" {
+				continue
+			}
+			targetName, targetPath := s.mapChart(name, targetName)
+			if err := s.checkMapping(mapped, i, name, targetName, targetPath, versions); err != nil {
+				return errors.Trace(err)
+			}
+
+			klog.V(5).Infof("Found %d versions for %q chart", len(versions), name)
+			klog.V(3).Infof("Indexing %q charts...", name)
+			if err := s.processVersions(&Chart{Name: name, Source: i, TargetName: targetName, TargetPath: targetPath}, versions, publishingThreshold); err != nil {
+				errs = goerrors.Join(errs, errors.Trace(err))
 			}
 		}
 	}
@@ -134,58 +164,173 @@ func (s *Syncer) loadCharts(charts ...string) error {
 	return errors.Trace(errs)
 }
 
+// resolveConflict returns the name of the chart in the targets for a chart
+// published by the i-th source, applying the conflict policy if a previous
+// source already publishes a chart with the same name. An empty name means the
+// chart should not be synced.
+func (s *Syncer) resolveConflict(owners map[string]int, i int, name string) (string, error) {
+	owner, ok := owners[name]
+	if !ok || owner == i {
+		owners[name] = i
+		return name, nil
+	}
+
+	switch s.conflictPolicy {
+	case api.ConflictPolicy_PREFIX:
+		prefixed := s.sources[i].GetPrefix() + name
+		if o, ok := owners[prefixed]; ok && o != i {
+			return "", errors.Annotatef(ErrChartConflict, "%q chart from %q conflicts with the %q chart from %q even after prefixing it",
+				name, s.sourceID(i), prefixed, s.sourceID(o))
+		}
+		klog.Warningf("%q chart from %q is also published by %q. Syncing it as %q", name, s.sourceID(i), s.sourceID(owner), prefixed)
+		owners[prefixed] = i
+		return prefixed, nil
+	case api.ConflictPolicy_FAIL:
+		return "", errors.Annotatef(ErrChartConflict, "%q chart is published by both %q and %q", name, s.sourceID(owner), s.sourceID(i))
+	default:
+		klog.Warningf("%q chart from %q is also published by %q. Skipping it", name, s.sourceID(i), s.sourceID(owner))
+		return "", nil
+	}
+}
+
+// processVersions loads the versions of a chart into the index. The chart
+// argument is used as a template for the indexed versions.
+func (s *Syncer) processVersions(chart *Chart, versions []string, publishingThreshold time.Time) error {
+	if s.latestVersionOnly {
+		latest, err := latestVersion(versions)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		versions = []string{latest}
+	}
+
+	var errs error
+	for _, version := range versions {
+		ch := *chart
+		ch.Version = version
+		ch.TargetVersion = suffixVersion(version, s.transform.GetVersionSuffix())
+		if err := s.processVersion(&ch, publishingThreshold); err != nil {
+			klog.Warningf("Failed processing %s:%s chart. The index will remain incomplete.", ch.Name, version)
+			errs = goerrors.Join(errs, errors.Trace(err))
+			continue
+		}
+	}
+	return errs
+}
+
 // processVersion takes care of loading a specific version of the chart into the index
-func (s *Syncer) processVersion(name, version string, publishingThreshold time.Time) error {
-	details, err := s.cli.src.GetChartDetails(name, version)
+func (s *Syncer) processVersion(ch *Chart, publishingThreshold time.Time) error {
+	src := s.cli.src[ch.Source]
+	details, err := src.GetChartDetails(ch.Name, ch.Version)
 	if err != nil {
 		return err
 	}
 
-	id := fmt.Sprintf("%s-%s", name, version)
+	id := ch.id()
 	if details.PublishedAt.Before(publishingThreshold) {
 		klog.V(5).Infof("Skipping %q chart: Published before %q", id, publishingThreshold.String())
 		return nil
 	}
 
-	if ok, err := s.cli.dst.Has(name, version); err != nil {
-		klog.Errorf("unable to explore target repo to check %q chart: %v", id, err)
-		return err
-	} else if ok {
+	for i := range s.cli.dst {
+		dst, err := s.targetClient(i, ch.TargetPath)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ok, err := dst.Has(ch.TargetName, ch.TargetVersion)
+		if err != nil {
+			klog.Errorf("unable to explore target repo to check %q chart: %v", id, err)
+			return err
+		}
+		if ok {
+			if ok, err = s.completed(i, ch, dst); err != nil {
+				klog.Errorf("unable to check whether %q chart was completely synced: %v", id, err)
+				return err
+			}
+		}
+		if !ok {
+			ch.Targets = append(ch.Targets, i)
+		}
+	}
+	if len(ch.Targets) == 0 {
 		klog.V(5).Infof("Skipping %q chart: Already synced", id)
 		return nil
 	}
 
-	if ch := s.getIndex().Get(id); ch != nil {
+	if c := s.getIndex().Get(id); c != nil {
 		klog.V(5).Infof("Skipping %q chart: Already indexed", id)
 		return nil
 	}
 
-	if err := s.loadChart(name, version); err != nil {
+	if err := s.loadChart(ch); err != nil {
 		klog.Errorf("unable to load %q chart: %v", id, err)
 		return err
 	}
 	return nil
 }
 
-// loadChart loads a chart in the chart index map
-func (s *Syncer) loadChart(name string, version string) error {
-	id := fmt.Sprintf("%s-%s", name, version)
+// loadChart fetches a chart and loads it in the chart index map
+func (s *Syncer) loadChart(ch *Chart) error {
+	id := ch.id()
 
-	tgz, err := s.cli.src.Fetch(name, version)
+	tgz, err := s.cli.src[ch.Source].Fetch(ch.Name, ch.Version)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	ch.TgzPath = tgz
 
-	ch := &Chart{
-		Name:    name,
-		Version: version,
-		TgzPath: tgz,
+	// Provenance files are needed to copy them and to verify the chart
+	if !s.skipArtifacts || s.keyring != nil {
+		if err := s.fetchProvenance(ch); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if err := s.verifyChart(ch); err != nil {
+		return errors.Trace(err)
+	}
+	if err := s.runChartHooks(hook.AfterFetch, ch, "", ""); err != nil {
+		return errors.Trace(err)
 	}
 
 	klog.V(4).Infof("Indexing %q chart", id)
 	return errors.Trace(s.getIndex().Add(id, ch))
 }
 
+// fetchProvenance fetches the provenance file of a chart, if its source
+// publishes one
+func (s *Syncer) fetchProvenance(ch *Chart) error {
+	r, ok := s.cli.src[ch.Source].(client.ProvenanceReader)
+	if !ok {
+		return nil
+	}
+	prov, err := r.FetchProvenance(ch.Name, ch.Version)
+	if errors.Is(err, errors.NotFound) || errors.Is(err, errors.NotSupported) {
+		klog.V(4).Infof("%q chart has no provenance file: %v", ch.id(), err)
+		return nil
+	}
+	if err != nil {
+		return errors.Annotatef(err, "fetching %q provenance file", ch.id())
+	}
+	ch.ProvPath = prov
+	return nil
+}
+
+// latestVersion returns the latest of a non-empty list of semver versions
+func latestVersion(versions []string) (string, error) {
+	vs := make([]*semver.Version, len(versions))
+	for i, r := range versions {
+		v, err := semver.NewVersion(r)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		vs[i] = v
+	}
+	sort.Sort(semver.Collection(vs))
+	// The last element of the array is the latest version
+	return vs[len(vs)-1].String(), nil
+}
+
 func shouldSkipChart(chartName string, skippedCharts []string) bool {
 	for _, s := range skippedCharts {
 		if s == chartName {
@@ -23,8 +23,8 @@ func TestLoadCharts(t *testing.T) {
 			desc:    "load apache and kafka",
 			entries: []string{"apache", "kafka"},
 			want: ChartIndex{
-				"apache-7.3.15": &Chart{Name: "apache", Version: "7.3.15"},
-				"kafka-10.3.3":  &Chart{Name: "kafka", Version: "10.3.3"},
+				"apache-7.3.15": &Chart{Name: "apache", TargetName: "apache", Version: "7.3.15", TargetVersion: "7.3.15", Targets: []int{0}},
+				"kafka-10.3.3":  &Chart{Name: "kafka", TargetName: "kafka", Version: "10.3.3", TargetVersion: "10.3.3", Targets: []int{0}},
 			},
 		},
 		{
@@ -32,7 +32,7 @@ func TestLoadCharts(t *testing.T) {
 			entries:        []string{"apache", "kafka", "zookeeper"},
 			skippedEntries: []string{"apache", "kafka"},
 			want: ChartIndex{
-				"zookeeper-5.14.3": &Chart{Name: "zookeeper", Version: "5.14.3"},
+				"zookeeper-5.14.3": &Chart{Name: "zookeeper", TargetName: "zookeeper", Version: "5.14.3", TargetVersion: "5.14.3", Targets: []int{0}},
 			},
 		},
 	}
@@ -0,0 +1,363 @@
+package syncer
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	ct "github.com/bitnami/charts-syncer/pkg/client/target"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/klog"
+)
+
+// Status of a chart verified in a target
+const (
+	// StatusComplete means the chart and all its images are in the target
+	StatusComplete = "complete"
+	// StatusPartial means the chart is in the target, but some of its images
+	// or platforms are missing, or it is missing from the charts index
+	StatusPartial = "partial"
+	// StatusBroken means the chart can not be used from the target
+	StatusBroken = "broken"
+)
+
+// verifyTimeout is the maximum time to verify the images of a chart
+const verifyTimeout = 5 * time.Minute
+
+// VerifiedChart is the result of verifying a chart version in a target
+type VerifiedChart struct {
+	Target  string `json:"target"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Digest  string `json:"digest,omitempty"`
+	// DigestVerified is whether the digest was checked against the charts
+	// index. It is not for the targets without a charts index.
+	DigestVerified bool `json:"digestVerified"`
+	// Images is the number of container images used by the chart
+	Images int    `json:"images"`
+	Status string `json:"status"`
+	// Problems found verifying the chart
+	Problems []string `json:"problems,omitempty"`
+}
+
+// problem records a problem found verifying the chart, downgrading its status
+func (c *VerifiedChart) problem(status, format string, args ...interface{}) {
+	c.Problems = append(c.Problems, fmt.Sprintf(format, args...))
+	if c.Status != StatusBroken {
+		c.Status = status
+	}
+}
+
+// VerifyTargets checks the charts in the targets are complete: the images
+// listed in their Images.lock file, or in their "images" annotation, exist in
+// the target registries for every container platform, and their digests are
+// recorded in the charts index of the targets publishing one. The digests of
+// the charts in other targets are reported as unverified. If no chart names
+// are provided, all the charts in the targets are verified.
+//
+// The WithTargets, WithChartMappings, WithSkipCharts, WithContainerPlatforms,
+// WithInsecure, WithUsePlainHTTP and WithWorkdir options are honored. The
+// charts that could be verified are returned even if there were problems
+// with others.
+func VerifyTargets(names []string, opts ...Option) ([]*VerifiedChart, error) {
+	s := &Syncer{cli: &Clients{}}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.workdir == "" {
+		s.workdir = DefaultWorkdir()
+	}
+	for _, t := range s.targets {
+		c, err := ct.NewClient(t, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		s.cli.dst = append(s.cli.dst, c)
+	}
+
+	// Charts can be stored in the sub-paths of the chart mappings too
+	paths := []string{""}
+	for _, m := range s.chartMappings {
+		if p := m.GetPath(); p != "" && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var verified []*VerifiedChart
+	var errs error
+	for i := range s.targets {
+		index, err := s.indexDigests(i)
+		if err != nil {
+			klog.Warningf("unable to read %q charts index: %v", s.targetID(i), err)
+			errs = goerrors.Join(errs, errors.Annotatef(err, "reading %q charts index", s.targetID(i)))
+		}
+		for _, p := range paths {
+			dst, err := s.targetClient(i, p)
+			if err != nil {
+				errs = goerrors.Join(errs, errors.Trace(err))
+				continue
+			}
+			versions, err := List(dst, names, WithSkipCharts(s.skipCharts))
+			if err != nil {
+				klog.Warningf("There were some problems listing the charts of %q: %v", s.targetID(i), err)
+				errs = goerrors.Join(errs, errors.Annotatef(err, "listing %q charts", s.targetID(i)))
+			}
+			for _, v := range versions {
+				verified = append(verified, s.verifyTargetChart(i, p, dst, v, index))
+			}
+		}
+	}
+	return verified, errs
+}
+
+// verifyTargetChart verifies a chart version stored in the provided sub-path
+// of the i-th target
+func (s *Syncer) verifyTargetChart(i int, subPath string, dst client.ChartsReader, v *ChartVersion, index map[string]string) *VerifiedChart {
+	target := s.targetID(i)
+	if subPath != "" {
+		target = fmt.Sprintf("%s/%s", strings.TrimSuffix(target, "/"), subPath)
+	}
+	c := &VerifiedChart{Target: target, Name: v.Name, Version: v.Version, Digest: v.Digest, Status: StatusComplete}
+	klog.V(3).Infof("Verifying %s:%s chart in %q...", v.Name, v.Version, target)
+
+	if index != nil {
+		d, ok := index[path.Join(subPath, v.Name)+":"+v.Version]
+		switch {
+		case !ok:
+			c.problem(StatusPartial, "not found in the charts index")
+		case d == "" || v.Digest == "":
+			klog.V(3).Infof("Unable to verify the digest of %s:%s chart: it is not recorded", v.Name, v.Version)
+		case d != v.Digest:
+			c.problem(StatusBroken, "digest %s does not match the charts index one: %s", v.Digest, d)
+		default:
+			c.DigestVerified = true
+		}
+	} else {
+		klog.V(3).Infof("Unable to verify the digest of %s:%s chart: %q does not publish a charts index", v.Name, v.Version, target)
+	}
+
+	tgz, err := dst.Fetch(v.Name, v.Version)
+	if err != nil {
+		c.problem(StatusBroken, "unable to fetch the chart: %v", err)
+		return c
+	}
+
+	// LOCAL targets store wraps, including the images
+	if s.targets[i].GetRepo().GetKind() == api.Kind_LOCAL {
+		lock, err := chartwrap.ReadImagesLock(tgz)
+		if err != nil {
+			c.problem(StatusBroken, "unable to read the Images.lock file: %v", err)
+			return c
+		}
+		c.Images = len(lock.Images.Dedup())
+		return c
+	}
+
+	images, err := chartImages(tgz)
+	if err != nil {
+		c.problem(StatusBroken, "unable to read the chart images: %v", err)
+		return c
+	}
+	c.Images = len(images)
+	s.verifyImages(i, c, images)
+	return c
+}
+
+// verifyImages checks the images of a chart exist in the i-th target
+func (s *Syncer) verifyImages(i int, c *VerifiedChart, images imagelock.ImageList) {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+
+	t := s.targets[i]
+	username, password := t.GetContainers().GetAuth().GetUsername(), t.GetContainers().GetAuth().GetPassword()
+	if username == "" {
+		username, password = t.GetRepo().GetAuth().GetUsername(), t.GetRepo().GetAuth().GetPassword()
+	}
+	registryURL := t.GetContainers().GetUrl()
+	if registryURL == "" {
+		registryURL = t.GetRepo().GetUrl()
+	}
+	nameOpts, opts, err := s.registryOptions(ctx, registryURL, username, password)
+	if err != nil {
+		c.problem(StatusBroken, "unable to access the target registry: %v", err)
+		return
+	}
+
+	missing := 0
+	for _, img := range images {
+		ref, err := name.ParseReference(img.Image, nameOpts...)
+		if err != nil {
+			c.problem(StatusBroken, "invalid %q image: %v", img.Image, err)
+			missing++
+			continue
+		}
+		var found bool
+		if len(img.Digests) == 0 {
+			// Images from annotations do not list their digests
+			found = s.verifyImagePlatforms(c, ref, opts)
+		} else {
+			found = s.verifyImageDigests(c, img, ref, opts)
+		}
+		if !found {
+			missing++
+		}
+	}
+	if missing > 0 && missing == len(images) {
+		c.problem(StatusBroken, "none of the %d images is available", len(images))
+	}
+}
+
+// verifyImageDigests checks the digests of an image listed in an Images.lock
+// file exist, for the configured platforms. It returns whether any of them
+// was found.
+func (s *Syncer) verifyImageDigests(c *VerifiedChart, img *imagelock.ChartImage, ref name.Reference, opts []remote.Option) bool {
+	found := false
+	digests := img.Digests
+	if len(s.containerPlatforms) > 0 {
+		digests = nil
+		for _, p := range s.containerPlatforms {
+			i := slices.IndexFunc(img.Digests, func(d imagelock.DigestInfo) bool { return d.Arch == p })
+			if i < 0 {
+				c.problem(StatusPartial, "%q image is missing the %s platform in Images.lock", img.Image, p)
+				continue
+			}
+			digests = append(digests, img.Digests[i])
+		}
+	}
+	for _, d := range digests {
+		_, err := remote.Head(ref.Context().Digest(d.Digest.String()), opts...)
+		if isNotFound(err) {
+			c.problem(StatusPartial, "%q image is missing the %s platform (%s)", img.Image, d.Arch, d.Digest)
+		} else if err != nil {
+			c.problem(StatusPartial, "unable to check %q image %s platform: %v", img.Image, d.Arch, err)
+		} else {
+			found = true
+		}
+	}
+	return found
+}
+
+// verifyImagePlatforms checks an image exists, including the configured
+// platforms. It returns whether the image was found.
+func (s *Syncer) verifyImagePlatforms(c *VerifiedChart, ref name.Reference, opts []remote.Option) bool {
+	desc, err := remote.Get(ref, opts...)
+	if isNotFound(err) {
+		c.problem(StatusPartial, "%q image is missing", ref)
+		return false
+	} else if err != nil {
+		c.problem(StatusPartial, "unable to check %q image: %v", ref, err)
+		return false
+	}
+	if len(s.containerPlatforms) == 0 || !desc.MediaType.IsIndex() {
+		return true
+	}
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		c.problem(StatusPartial, "unable to read %q image index: %v", ref, err)
+		return true
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		c.problem(StatusPartial, "unable to read %q image index: %v", ref, err)
+		return true
+	}
+	for _, p := range s.containerPlatforms {
+		found := false
+		for _, m := range manifest.Manifests {
+			if m.Platform != nil && m.Platform.String() == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.problem(StatusPartial, "%q image is missing the %s platform", ref, p)
+		}
+	}
+	return true
+}
+
+// indexDigests returns the digests of the charts recorded in the charts index
+// of the i-th target, indexed by "<name>:<version>". A nil map is returned if
+// the target does not publish a charts index.
+func (s *Syncer) indexDigests(i int) (map[string]string, error) {
+	t := s.targets[i]
+	if !t.GetPublishChartsIndex() {
+		return nil, nil
+	}
+	pub, err := s.indexPublisher(i)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+	idx, err := pub.Get(ctx)
+	if indexer.IsNotFound(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	digests := make(map[string]string)
+	for _, entries := range idx.GetEntries() {
+		for _, e := range entries.GetVersions() {
+			digests[e.GetName()+":"+e.GetVersion()] = e.GetDigest()
+		}
+	}
+	return digests, nil
+}
+
+// chartImages returns the images of a packaged chart, listed in its
+// Images.lock file or, if it has none, in its "images" annotations
+func chartImages(tgz string) (imagelock.ImageList, error) {
+	c, err := loader.Load(tgz)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, f := range c.Files {
+		if f.Name == imagelock.DefaultImagesLockFileName {
+			lock, err := imagelock.FromYAML(strings.NewReader(string(f.Data)))
+			if err != nil {
+				return nil, errors.Annotatef(err, "reading %s", f.Name)
+			}
+			return lock.Images.Dedup(), nil
+		}
+	}
+
+	cfg := imagelock.NewImagesLockConfig()
+	images, err := imagelock.GetImagesFromChartAnnotations(c, cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, dep := range c.Dependencies() {
+		depImages, err := imagelock.GetImagesFromChartAnnotations(dep, cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		images = append(images, depImages...)
+	}
+	return images.Dedup(), nil
+}
+
+// isNotFound returns whether a registry error is a not found one
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return goerrors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
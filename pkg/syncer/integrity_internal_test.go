@@ -0,0 +1,140 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/client/repo/oci"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/imagelock"
+)
+
+func TestVerifyImages(t *testing.T) {
+	repo := &api.Repo{Kind: api.Kind_OCI}
+	oci.PrepareOCIServer(context.Background(), t, repo)
+	registry := strings.TrimPrefix(repo.GetUrl(), "http://")
+
+	// Push a multi-platform image only including linux/amd64
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        img,
+		Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+	})
+	ref, err := name.ParseReference(registry+"/bitnami/apache:2.4.43", name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteIndex(ref, idx))
+	imgDigest, err := img.Digest()
+	require.NoError(t, err)
+
+	annotated := imagelock.ImageList{{Name: "apache", Image: ref.String()}}
+	locked := imagelock.ImageList{{Name: "apache", Image: ref.String(), Digests: []imagelock.DigestInfo{
+		{Digest: digest.Digest(imgDigest.String()), Arch: "linux/amd64"},
+		{Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000", Arch: "linux/arm64"},
+	}}}
+	missing := imagelock.ImageList{{Name: "apache", Image: registry + "/bitnami/missing:1.0.0"}}
+
+	tests := []struct {
+		desc      string
+		images    imagelock.ImageList
+		platforms []string
+		status    string
+		problems  []string
+	}{
+		{
+			desc:   "annotated image",
+			images: annotated,
+			status: StatusComplete,
+		},
+		{
+			desc:      "annotated image missing a platform",
+			images:    annotated,
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			status:    StatusPartial,
+			problems:  []string{`image is missing the linux/arm64 platform`},
+		},
+		{
+			desc:      "locked image",
+			images:    locked,
+			platforms: []string{"linux/amd64"},
+			status:    StatusComplete,
+		},
+		{
+			desc:     "locked image missing a digest",
+			images:   append(locked, annotated...),
+			status:   StatusPartial,
+			problems: []string{`image is missing the linux/arm64 platform (sha256:0000`},
+		},
+		{
+			desc:     "missing image",
+			images:   missing,
+			status:   StatusBroken,
+			problems: []string{`"` + registry + `/bitnami/missing:1.0.0" image is missing`, "none of the 1 images is available"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &Syncer{
+				targets:            []*api.Target{{Repo: repo}},
+				containerPlatforms: tc.platforms,
+				usePlainHTTP:       true,
+			}
+			c := &VerifiedChart{Status: StatusComplete}
+			s.verifyImages(0, c, tc.images)
+			assert.Equal(t, tc.status, c.Status)
+			require.Len(t, c.Problems, len(tc.problems), "%v", c.Problems)
+			for i, p := range tc.problems {
+				assert.Contains(t, c.Problems[i], p)
+			}
+		})
+	}
+}
+
+// missingReader is a charts reader unable to fetch any chart
+type missingReader struct {
+	client.ChartsReader
+}
+
+func (r *missingReader) Fetch(string, string) (string, error) {
+	return "", errors.New("not found")
+}
+
+func TestVerifyTargetChartDigest(t *testing.T) {
+	s := &Syncer{targets: []*api.Target{{Repo: &api.Repo{Kind: api.Kind_OCI, Url: "https://registry.example.com/charts"}}}}
+	v := &ChartVersion{Name: "apache", Version: "7.3.15", Digest: "sha256:1111"}
+
+	tests := []struct {
+		desc         string
+		index        map[string]string
+		wantVerified bool
+		wantProblem  string
+	}{
+		{desc: "no charts index"},
+		{desc: "matching digest", index: map[string]string{"apache:7.3.15": "sha256:1111"}, wantVerified: true},
+		{desc: "digest not recorded", index: map[string]string{"apache:7.3.15": ""}},
+		{desc: "different digest", index: map[string]string{"apache:7.3.15": "sha256:2222"}, wantProblem: "does not match the charts index"},
+		{desc: "missing from the charts index", index: map[string]string{}, wantProblem: "not found in the charts index"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := s.verifyTargetChart(0, "", &missingReader{}, v, tc.index)
+			assert.Equal(t, tc.wantVerified, c.DigestVerified)
+			if tc.wantProblem != "" {
+				assert.Contains(t, strings.Join(c.Problems, "; "), tc.wantProblem)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package syncer
+
+import (
+	goerrors "errors"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// ChartVersion describes a version of a chart published in a repository
+type ChartVersion struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Digest      string    `json:"digest,omitempty"`
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	// Annotations are the chart annotations, if the repository index
+	// records them
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// List returns the chart versions published in a repository, sorted by name
+// and version. If no chart names are provided, all the charts in the
+// repository are listed.
+//
+// The same filters used to sync charts are applied, so the WithSkipCharts,
+// WithFromDate and WithLatestVersionOnly options are honored. The versions
+// that could be listed are returned even if there were problems with others.
+func List(r client.ChartsReader, names []string, opts ...Option) ([]*ChartVersion, error) {
+	s := &Syncer{}
+	for _, o := range opts {
+		o(s)
+	}
+
+	publishingThreshold, err := utils.GetDateThreshold(s.fromDate)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if len(names) == 0 {
+		if names, err = r.List(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	var list []*ChartVersion
+	var errs error
+	for _, name := range names {
+		if shouldSkipChart(name, s.skipCharts) {
+			klog.V(3).Infof("Listing %q charts SKIPPED...", name)
+			continue
+		}
+
+		versions, err := r.ListChartVersions(name)
+		if err != nil {
+			errs = goerrors.Join(errs, errors.Annotatef(err, "listing %q chart versions", name))
+			continue
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		if s.latestVersionOnly {
+			latest, err := latestVersion(versions)
+			if err != nil {
+				errs = goerrors.Join(errs, errors.Annotatef(err, "looking for the latest %q chart version", name))
+				continue
+			}
+			versions = []string{latest}
+		}
+		sortVersions(versions)
+
+		for _, version := range versions {
+			details, err := r.GetChartDetails(name, version)
+			if err != nil {
+				errs = goerrors.Join(errs, errors.Annotatef(err, "getting %s:%s chart details", name, version))
+				continue
+			}
+			if details.PublishedAt.Before(publishingThreshold) {
+				continue
+			}
+			list = append(list, &ChartVersion{Name: name, Version: version, PublishedAt: details.PublishedAt, Digest: details.Digest, Deprecated: details.Deprecated, Annotations: details.Annotations})
+		}
+	}
+	return list, errs
+}
+
+// sortVersions sorts versions in ascending semver order. Invalid versions are
+// sorted alphabetically after the valid ones.
+func sortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := semver.NewVersion(versions[i])
+		vj, errj := semver.NewVersion(versions[j])
+		switch {
+		case erri == nil && errj == nil:
+			return vi.LessThan(vj)
+		case erri != nil && errj != nil:
+			return versions[i] < versions[j]
+		default:
+			return erri == nil
+		}
+	})
+}
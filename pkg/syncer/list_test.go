@@ -0,0 +1,88 @@
+package syncer_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/bitnami/charts-syncer/pkg/syncer"
+)
+
+// fakeReader is a charts reader serving versions published a day apart
+type fakeReader struct {
+	entries map[string][]string
+}
+
+func (r *fakeReader) Fetch(_, _ string) (string, error)            { return "", nil }
+func (r *fakeReader) Has(_, _ string) (bool, error)                { return false, nil }
+func (r *fakeReader) Reload() error                                { return nil }
+func (r *fakeReader) ListChartVersions(n string) ([]string, error) { return r.entries[n], nil }
+
+func (r *fakeReader) List() ([]string, error) {
+	var names []string
+	for n := range r.entries {
+		names = append(names, n)
+	}
+	return names, nil
+}
+
+func (r *fakeReader) GetChartDetails(name, version string) (*types.ChartDetails, error) {
+	for i, v := range r.entries[name] {
+		if v == version {
+			return &types.ChartDetails{PublishedAt: time.Date(2020, 5, 1+i, 0, 0, 0, 0, time.UTC), Digest: name + "-" + v}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestList(t *testing.T) {
+	r := &fakeReader{entries: map[string][]string{
+		"redis":   {"1.0.0", "1.10.0", "1.2.0"},
+		"mariadb": {"2.0.0"},
+		"kafka":   {"3.0.0"},
+	}}
+	tests := []struct {
+		desc  string
+		names []string
+		opts  []syncer.Option
+		want  []string
+	}{
+		{
+			desc: "all charts",
+			want: []string{"kafka-3.0.0", "mariadb-2.0.0", "redis-1.0.0", "redis-1.2.0", "redis-1.10.0"},
+		},
+		{
+			desc:  "some charts",
+			names: []string{"redis", "kafka"},
+			opts:  []syncer.Option{syncer.WithSkipCharts([]string{"kafka"})},
+			want:  []string{"redis-1.0.0", "redis-1.2.0", "redis-1.10.0"},
+		},
+		{
+			desc: "latest versions",
+			opts: []syncer.Option{syncer.WithLatestVersionOnly(true), syncer.WithSkipCharts([]string{"mariadb"})},
+			want: []string{"kafka-3.0.0", "redis-1.10.0"},
+		},
+		{
+			desc:  "from date",
+			names: []string{"redis"},
+			opts:  []syncer.Option{syncer.WithFromDate("2020-05-02")},
+			want:  []string{"redis-1.2.0", "redis-1.10.0"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			versions, err := syncer.List(r, tc.names, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range versions {
+				got = append(got, v.Digest)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
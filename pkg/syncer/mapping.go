@@ -0,0 +1,97 @@
+package syncer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	ct "github.com/bitnami/charts-syncer/pkg/client/target"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/juju/errors"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog"
+)
+
+// mapChart applies the first chart mapping rule matching the source chart
+// name. It returns the name of the chart in the targets and the path,
+// relative to the targets, where the chart is stored. The prefix added to
+// the target name to resolve a conflict between sources is kept when the
+// rule renames the chart.
+func (s *Syncer) mapChart(name, targetName string) (string, string) {
+	for _, m := range s.chartMappings {
+		if !m.Matches(name) {
+			continue
+		}
+		if m.GetName() != "" {
+			targetName = strings.TrimSuffix(targetName, name) + m.GetName()
+		}
+		targetName = m.GetPrefix() + targetName
+		klog.V(4).Infof("Mapping %q chart to %q", name, strings.TrimPrefix(m.GetPath()+"/"+targetName, "/"))
+		return targetName, m.GetPath()
+	}
+	return targetName, ""
+}
+
+// mappedChart identifies a source chart mapped to a chart in the targets
+type mappedChart struct {
+	source int
+	name   string
+}
+
+// checkMapping returns an error if any version of a source chart is stored
+// in the targets with the same path, name and version as a different source
+// chart. The mapped argument links the stored versions with their source
+// charts.
+func (s *Syncer) checkMapping(mapped map[string]mappedChart, i int, name, targetName, targetPath string, versions []string) error {
+	for _, version := range versions {
+		targetVersion := suffixVersion(version, s.transform.GetVersionSuffix())
+		key := fmt.Sprintf("%s/%s-%s", targetPath, targetName, targetVersion)
+		owner, ok := mapped[key]
+		if ok && owner != (mappedChart{source: i, name: name}) {
+			return errors.Annotatef(ErrChartConflict, "%q chart from %q and %q chart from %q are both synced as %q",
+				owner.name, s.sourceID(owner.source), name, s.sourceID(i), strings.TrimPrefix(key, "/"))
+		}
+		mapped[key] = mappedChart{source: i, name: name}
+	}
+	return nil
+}
+
+// targetClient returns the client for the i-th target, pointing to the
+// provided sub-path. The clients for the sub-paths are created on demand.
+func (s *Syncer) targetClient(i int, subPath string) (client.ChartsUnwrapper, error) {
+	if subPath == "" {
+		return s.cli.dst[i], nil
+	}
+
+	key := fmt.Sprintf("%d/%s", i, subPath)
+	if c, ok := s.cli.dstPaths[key]; ok {
+		return c, nil
+	}
+
+	t := proto.Clone(s.targets[i]).(*api.Target)
+	repo := t.GetRepo()
+	if repo.GetKind() == api.Kind_LOCAL {
+		repo.Path = filepath.Join(repo.GetPath(), subPath)
+	} else {
+		// Container images are kept in the target root unless told otherwise
+		if t.GetContainers().GetUrl() == "" {
+			if t.Containers == nil {
+				t.Containers = &api.Containers{}
+			}
+			t.Containers.Url = repo.GetUrl()
+		}
+		repo.Url = fmt.Sprintf("%s/%s", strings.TrimSuffix(repo.GetUrl(), "/"), subPath)
+	}
+
+	c, err := ct.NewClient(t, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
+	if err != nil {
+		return nil, errors.Annotatef(err, "creating client for %q path of %q target", subPath, s.targetID(i))
+	}
+	if s.cli.dstPaths == nil {
+		s.cli.dstPaths = make(map[string]client.ChartsUnwrapper)
+	}
+	s.cli.dstPaths[key] = c
+	return c, nil
+}
@@ -0,0 +1,146 @@
+package syncer
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	indexapi "github.com/bitnami/charts-syncer/internal/indexer/api"
+	"github.com/juju/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"k8s.io/klog"
+)
+
+// publishTimeout is the maximum time to wait for a charts index to be updated
+const publishTimeout = 5 * time.Minute
+
+// markSynced records that a chart was synced into the i-th target
+func (s *Syncer) markSynced(i int, ch *Chart) {
+	if s.synced == nil {
+		s.synced = make(map[int][]*Chart)
+	}
+	s.synced[i] = append(s.synced[i], ch)
+}
+
+// publishIndexes adds the synced charts to the charts index of the targets
+// publishing one
+func (s *Syncer) publishIndexes() error {
+	var errs error
+	for i, t := range s.targets {
+		if !t.GetPublishChartsIndex() || len(s.synced[i]) == 0 {
+			continue
+		}
+		ref := t.GetRepo().GetChartsIndex()
+		if s.dryRun {
+			klog.Infof("dry-run: Adding %d charts to %q charts index", len(s.synced[i]), ref)
+			if t.GetSigning().GetSignChartsIndex() {
+				klog.Infof("dry-run: Signing %q charts index", ref)
+			}
+			continue
+		}
+		if err := s.logger.ExecuteStep(fmt.Sprintf("Updating %q charts index", ref), func() error {
+			return s.publishIndex(i)
+		}); err != nil {
+			klog.Errorf("unable to update %q charts index: %+v", ref, err)
+			errs = goerrors.Join(errs, errors.Annotatef(err, "updating %q charts index", ref))
+		}
+	}
+	return errs
+}
+
+// publishIndex merges the charts synced into the i-th target with its current
+// charts index, and pushes the result
+func (s *Syncer) publishIndex(i int) error {
+	repo := s.targets[i].GetRepo()
+	pub, err := s.indexPublisher(i)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	idx, err := pub.Get(ctx)
+	if indexer.IsNotFound(err) {
+		klog.V(3).Infof("%q charts index does not exist yet. Creating it", repo.GetChartsIndex())
+		idx, err = &indexapi.Index{ApiVersion: "v1"}, nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	u, err := url.Parse(repo.GetUrl())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, ch := range s.synced[i] {
+		entry, err := s.indexEntry(i, ch, path.Join(u.Host, u.Path))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		idx.Add(entry)
+	}
+	if err := pub.Push(ctx, idx); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(s.signIndex(ctx, i))
+}
+
+// indexPublisher returns the publisher of the charts index of the i-th target
+func (s *Syncer) indexPublisher(i int) (indexer.Publisher, error) {
+	repo := s.targets[i].GetRepo()
+	opts := []indexer.OciIndexerOpt{
+		indexer.WithHost(repo.GetUrl()),
+		indexer.WithBasicAuth(repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword()),
+		indexer.WithIndexRef(repo.GetChartsIndex()),
+	}
+	if s.insecure {
+		opts = append(opts, indexer.WithInsecure())
+	}
+	pub, err := indexer.NewOciPublisher(opts...)
+	return pub, errors.Trace(err)
+}
+
+// indexEntry returns the charts index entry of a chart synced into the i-th
+// target. The name of charts stored in target sub-paths includes the path, so
+// they can be found relative to the target.
+func (s *Syncer) indexEntry(i int, ch *Chart, repoURI string) (*indexapi.ChartMetadata, error) {
+	dst, err := s.targetClient(i, ch.TargetPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	details, err := dst.GetChartDetails(ch.TargetName, ch.TargetVersion)
+	if err != nil {
+		return nil, errors.Annotatef(err, "getting %q chart details", ch.id())
+	}
+
+	name := path.Join(ch.TargetPath, ch.TargetName)
+	entry := &indexapi.ChartMetadata{
+		Name:    name,
+		Version: ch.TargetVersion,
+		Digest:  details.Digest,
+		// helm replaces plus(+) characters with underscores(_) in the tag (version)
+		Urls: []string{fmt.Sprintf("%s/%s:%s", repoURI, name, strings.ReplaceAll(ch.TargetVersion, "+", "_"))},
+	}
+	if !details.PublishedAt.IsZero() {
+		entry.ReleasedAt = details.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	// The metadata is read from the chart pushed to the target, as relocating
+	// or transforming the chart changes it
+	tgz, err := dst.Fetch(ch.TargetName, ch.TargetVersion)
+	if err != nil {
+		return nil, errors.Annotatef(err, "fetching %q chart from the target", ch.id())
+	}
+	c, err := loader.Load(tgz)
+	if err != nil {
+		return nil, errors.Annotatef(err, "loading %q chart from the target", ch.id())
+	}
+	entry.AppVersion = c.AppVersion()
+	entry.Deprecated = c.Metadata.Deprecated
+	entry.Annotations = c.Metadata.Annotations
+	return entry, nil
+}
@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+)
+
+// SyncedChart is a chart synced into a target
+type SyncedChart struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Target  string `json:"target"`
+	// Artifacts are the artifacts attached to the chart copied along with it
+	Artifacts []*types.Artifact `json:"artifacts,omitempty"`
+	// Unverified is the reason why the chart failed verification, if it was
+	// synced anyway
+	Unverified string `json:"unverified,omitempty"`
+	// Signed is whether the chart was signed with the target signing key
+	Signed bool `json:"signed,omitempty"`
+	// Warnings are the warnings of the gates checking the chart before
+	// publishing it, and of the artifacts that could not be copied with it
+	Warnings []string `json:"warnings,omitempty"`
+	// Annotations are the annotations added by the hooks
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Report summarizes the charts synced by a run
+type Report struct {
+	Charts []*SyncedChart `json:"charts"`
+	// Annotations are the annotations added by the hooks run before indexing
+	// the charts and at the end of the sync
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Quotas are the last request quotas announced by the registries and
+	// chart repositories
+	Quotas []*throttle.Quota `json:"quotas,omitempty"`
+	// Deferred are the charts wrapped outside the allowed windows, to be
+	// pushed by a sync inside the next one
+	Deferred []*DeferredChart `json:"deferred,omitempty"`
+	// NextWindow is when the next allowed window opens, if the charts were
+	// deferred
+	NextWindow *time.Time `json:"nextWindow,omitempty"`
+}
+
+// DeferredChart is a chart whose push was deferred to the next allowed window
+type DeferredChart struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Report returns the report of the charts synced so far
+func (s *Syncer) Report() *Report {
+	s.report.Quotas = throttle.Quotas()
+	return &s.report
+}
+
+// addToReport records a chart synced into the i-th target
+func (s *Syncer) addToReport(i int, ch *Chart, artifacts []*types.Artifact, signed bool) {
+	target := s.targetID(i)
+	if ch.TargetPath != "" {
+		target = fmt.Sprintf("%s/%s", strings.TrimSuffix(target, "/"), ch.TargetPath)
+	}
+	s.report.Charts = append(s.report.Charts, &SyncedChart{
+		Name:       ch.TargetName,
+		Version:    ch.TargetVersion,
+		Target:     target,
+		Artifacts:  artifacts,
+		Unverified: ch.Unverified,
+		Signed:     signed,
+		Warnings:   ch.Warnings,
+		// Later hooks could annotate the chart for the next targets
+		Annotations: maps.Clone(ch.Annotations),
+	})
+}
+
+// annotateReport adds annotations to the report
+func (s *Syncer) annotateReport(annotations map[string]string) {
+	for k, v := range annotations {
+		if s.report.Annotations == nil {
+			s.report.Annotations = make(map[string]string)
+		}
+		s.report.Annotations[k] = v
+	}
+}
+
+// logReport logs the charts synced with artifacts or failing verification
+func (s *Syncer) logReport() {
+	for _, c := range s.report.Charts {
+		if c.Unverified != "" {
+			s.logger.Warnf("%s-%s chart synced to %q without passing verification: %s", c.Name, c.Version, c.Target, c.Unverified)
+		}
+		for _, w := range c.Warnings {
+			s.logger.Warnf("%s-%s chart synced to %q with warnings: %s", c.Name, c.Version, c.Target, w)
+		}
+		if len(c.Artifacts) == 0 {
+			continue
+		}
+		s.logger.Infof("%s-%s chart synced to %q with %s", c.Name, c.Version, c.Target, describeArtifacts(c.Artifacts))
+	}
+}
+
+// describeArtifacts returns a human readable summary of artifacts, i.e
+// "1 sbom, 2 signatures"
+func describeArtifacts(artifacts []*types.Artifact) string {
+	count := make(map[string]int)
+	for _, a := range artifacts {
+		count[a.Kind]++
+	}
+	kinds := make([]string, 0, len(count))
+	for k := range count {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		if count[k] == 1 {
+			parts = append(parts, fmt.Sprintf("1 %s", k))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", count[k], k))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,19 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+)
+
+func TestDescribeArtifacts(t *testing.T) {
+	artifacts := []*types.Artifact{
+		{Kind: types.ArtifactKindSignature},
+		{Kind: types.ArtifactKindSBOM},
+		{Kind: types.ArtifactKindSignature},
+	}
+	want := "1 sbom, 2 signatures"
+	if got := describeArtifacts(artifacts); got != want {
+		t.Errorf("unexpected description. got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package syncer
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/bitnami/charts-syncer/internal/indexer"
+	"github.com/bitnami/charts-syncer/internal/sign"
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// registryOptions returns the options to access the registry of a repository
+// URL with the provided credentials
+func (s *Syncer) registryOptions(ctx context.Context, repoURL, username, password string) ([]name.Option, []remote.Option, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var nameOpts []name.Option
+	if u.Scheme == "http" || s.usePlainHTTP {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+
+	opts := []remote.Option{remote.WithContext(ctx)}
+	if username != "" && password != "" {
+		opts = append(opts, remote.WithAuth(&authn.Basic{Username: username, Password: password}))
+	}
+	opts = append(opts, remote.WithTransport(throttle.RegistryTransport(s.insecure)))
+	return nameOpts, opts, nil
+}
+
+// loadSigners loads the private keys of the targets signing the charts pushed
+// to them
+func (s *Syncer) loadSigners() error {
+	for i, t := range s.targets {
+		signing := t.GetSigning()
+		if signing == nil {
+			continue
+		}
+		signer, err := sign.LoadPrivateKey(signing.GetPrivateKey(), signing.GetPassword())
+		if err != nil {
+			return errors.Annotatef(err, "loading %q signing key", s.targetID(i))
+		}
+		if s.signers == nil {
+			s.signers = make(map[int]*sign.Signer)
+		}
+		s.signers[i] = signer
+	}
+	return nil
+}
+
+// signChart signs a chart pushed to the i-th target, if the target signs
+// charts. It returns whether the chart was signed.
+func (s *Syncer) signChart(i int, ch *Chart, dst client.ChartsUnwrapper) (bool, error) {
+	signer, ok := s.signers[i]
+	if !ok {
+		return false, nil
+	}
+	cs, ok := dst.(client.ChartSigner)
+	if !ok {
+		return false, errors.NotSupportedf("signing charts in %q", s.targetID(i))
+	}
+	return true, errors.Trace(cs.SignChart(ch.TargetName, ch.TargetVersion, signer))
+}
+
+// signIndex signs the charts index of the i-th target, if the target signs it
+func (s *Syncer) signIndex(ctx context.Context, i int) error {
+	signer, ok := s.signers[i]
+	if !ok || !s.targets[i].GetSigning().GetSignChartsIndex() {
+		return nil
+	}
+	repo := s.targets[i].GetRepo()
+	nameOpts, opts, err := s.registryOptions(ctx, repo.GetUrl(), repo.GetAuth().GetUsername(), repo.GetAuth().GetPassword())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ref, err := name.ParseReference(indexer.Reference(repo.GetChartsIndex()), nameOpts...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tag, err := signer.SignRemote(ref, opts...)
+	if err != nil {
+		return errors.Annotatef(err, "signing %q charts index", ref)
+	}
+	klog.V(3).Infof("%q charts index signed in %q", ref, tag)
+	return nil
+}
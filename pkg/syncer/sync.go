@@ -5,10 +5,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
 	"github.com/bitnami/charts-syncer/pkg/client/config"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/bitnami/charts-syncer/pkg/hook"
 	"github.com/juju/errors"
 	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
 	helmchart "helm.sh/helm/v3/pkg/chart"
 	"k8s.io/klog"
 )
@@ -16,8 +26,13 @@ import (
 // ErrNoChartsToSync is returned when there are no charts to sync
 var ErrNoChartsToSync = errors.New("no charts to sync")
 
+// ErrChartConflict is returned when several sources publish a chart with the
+// same name and the conflict policy does not allow it, or when several charts
+// are mapped to the same chart in the targets
+var ErrChartConflict = errors.New("chart published by several sources")
+
 func (s *Syncer) syncChart(ch *Chart, l log.SectionLogger) error {
-	id := fmt.Sprintf("%s-%s", ch.Name, ch.Version)
+	id := ch.id()
 	klog.Infof("Syncing %q chart...", id)
 
 	klog.V(3).Infof("Processing %q chart...", id)
@@ -37,43 +52,286 @@ func (s *Syncer) syncChart(ch *Chart, l log.SectionLogger) error {
 
 	// Some client Upload() methods needs this info
 	metadata := &helmchart.Metadata{
-		Name:    ch.Name,
-		Version: ch.Version,
+		Name:    ch.TargetName,
+		Version: ch.TargetVersion,
 	}
 
-	wrappedChartPath, err := s.cli.src.Wrap(ch.TgzPath,
-		filepath.Join(workdir, "wraps", fmt.Sprintf("%s-%s.wrap.tgz", ch.Name, ch.Version)),
-		config.WithLogger(l), config.WithWorkDir(workdir),
-		config.WithContainerPlatforms(s.containerPlatforms), config.WithSkipArtifacts(s.skipArtifacts),
-	)
-	if err != nil {
-		return errors.Annotatef(err, "unable to move chart %q with charts-syncer", id)
+	// Charts deferred by a sync outside the allowed windows are already wrapped
+	prewrappedPath := s.prewrapped(ch)
+	wrappedChartPath := prewrappedPath
+	if prewrappedPath != "" {
+		klog.V(3).Infof("Using %q chart wrapped by a previous sync: %q", id, prewrappedPath)
+	} else {
+		err = throttle.RetryTransfer(func() error {
+			var err error
+			wrappedChartPath, err = s.cli.src[ch.Source].Wrap(ch.TgzPath,
+				filepath.Join(workdir, "wraps", fmt.Sprintf("%s-%s.wrap.tgz", ch.Name, ch.Version)),
+				config.WithLogger(l), config.WithWorkDir(workdir),
+				config.WithContainerPlatforms(s.containerPlatforms), config.WithSkipArtifacts(s.skipArtifacts),
+				config.WithImageDiscovery(s.imageDiscovery),
+			)
+			return err
+		})
+		if err != nil {
+			return errors.Annotatef(err, "unable to move chart %q with charts-syncer", id)
+		}
+	}
+
+	if ch.TargetName != ch.Name || s.transform != nil {
+		rewrittenChartPath := filepath.Join(workdir, "wraps", fmt.Sprintf("%s.wrap.tgz", id))
+		if err := chartwrap.Rewrite(wrappedChartPath, rewrittenChartPath, func(w wrapping.Wrap) error {
+			if ch.TargetName != ch.Name {
+				klog.V(3).Infof("Renaming %q chart to %q...", ch.Name, ch.TargetName)
+				if err := chartwrap.SetName(w, ch.TargetName); err != nil {
+					return errors.Annotatef(err, "unable to rename %q chart to %q", ch.Name, ch.TargetName)
+				}
+			}
+			return errors.Trace(s.transformChart(w, ch))
+		}); err != nil {
+			return errors.Trace(err)
+		}
+		wrappedChartPath = rewrittenChartPath
+	}
+
+	// Charts failing the gates never reach the targets
+	if err := s.checkGates(ch, wrappedChartPath, metadata); err != nil {
+		return errors.Trace(err)
+	}
+	if err := s.runChartHooks(hook.AfterWrap, ch, wrappedChartPath, ""); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Artifacts attached to the chart, like signatures, are copied along with it
+	var artifacts []*types.Artifact
+	if !s.skipArtifacts {
+		if r, ok := s.cli.src[ch.Source].(client.ArtifactsReader); ok {
+			if artifacts, err = r.ListArtifacts(ch.Name, ch.Version); err != nil {
+				return errors.Annotatef(err, "unable to list %q chart artifacts", id)
+			}
+		}
+	}
+
+	// The provenance file is copied along with the chart too, and reported as
+	// another artifact
+	var provPath string
+	if !s.skipArtifacts {
+		provPath = ch.ProvPath
+	}
+	reported := artifacts
+	if provPath != "" {
+		reported = append([]*types.Artifact{{Kind: types.ArtifactKindProvenance}}, artifacts...)
 	}
 
 	if s.dryRun {
-		klog.Infof("dry-run: Uploading %q chart", id)
-		return nil
+		s.logImages(ch, wrappedChartPath)
 	}
 
-	klog.V(3).Infof("Uploading %q chart...", id)
+	// The chart is wrapped once and unwrapped into every target missing it
+	var errs error
+	for _, t := range ch.Targets {
+		target := s.targetID(t)
+		if ch.TargetPath != "" {
+			target = fmt.Sprintf("%s/%s", strings.TrimSuffix(target, "/"), ch.TargetPath)
+		}
+		if s.dryRun {
+			klog.Infof("dry-run: Uploading %q chart to %q", id, target)
+			if len(reported) > 0 {
+				klog.Infof("dry-run: Attaching %s to %q chart", describeArtifacts(reported), id)
+			}
+			_, signed := s.signers[t]
+			if signed {
+				klog.Infof("dry-run: Signing %q chart", id)
+			}
+			s.markSynced(t, ch)
+			s.addToReport(t, ch, reported, signed)
+			continue
+		}
+
+		klog.V(3).Infof("Uploading %q chart to %q...", id, target)
 
-	if err := s.cli.dst.Unwrap(wrappedChartPath, metadata, config.WithLogger(l), config.WithWorkDir(workdir)); err != nil {
-		klog.Errorf("unable to upload %q chart: %+v", id, err)
-		return errors.Trace(err)
+		dst, err := s.targetClient(t, ch.TargetPath)
+		if err != nil {
+			errs = goerrors.Join(errs, errors.Trace(err))
+			continue
+		}
+		if err := throttle.RetryTransfer(func() error {
+			return dst.Unwrap(wrappedChartPath, metadata, config.WithLogger(l), config.WithWorkDir(workdir))
+		}); err != nil {
+			klog.Errorf("unable to upload %q chart to %q: %+v", id, target, err)
+			errs = goerrors.Join(errs, errors.Annotatef(err, "uploading %q chart to %q", id, target))
+			continue
+		}
+		// Provenance files, signatures and attestations are made over the
+		// source chart, so they do not verify charts modified while syncing
+		// them
+		targetProvPath, targetArtifacts := provPath, artifacts
+		kept, signatures := splitSignatures(artifacts)
+		if (provPath != "" || len(signatures) > 0) && !s.chartUnchanged(t, ch, dst) {
+			if provPath != "" {
+				klog.Warningf("%q chart was modified while syncing it to %q, so its provenance file is not copied", id, target)
+				ch.Warnings = append(ch.Warnings, fmt.Sprintf("provenance file not copied to %q: the chart was modified, so it would not verify", target))
+				targetProvPath = ""
+			}
+			if len(signatures) > 0 {
+				klog.Warningf("%q chart was modified while syncing it to %q, so its %s are not copied", id, target, describeArtifacts(signatures))
+				ch.Warnings = append(ch.Warnings, fmt.Sprintf("%s not copied to %q: the chart was modified, so they would not verify", describeArtifacts(signatures), target))
+				targetArtifacts = kept
+			}
+		}
+		targetReported := targetArtifacts
+		if targetProvPath != "" {
+			targetReported = append([]*types.Artifact{{Kind: types.ArtifactKindProvenance}}, targetArtifacts...)
+		}
+		// Provenance files are added to the chart manifest, changing its digest,
+		// so they are pushed before attaching artifacts to the chart
+		if targetProvPath != "" {
+			if w, ok := dst.(client.ProvenanceWriter); ok {
+				if err := w.PushProvenance(ch.TargetName, ch.TargetVersion, targetProvPath); err != nil {
+					klog.Errorf("unable to push %q chart provenance file to %q: %+v", id, target, err)
+					errs = goerrors.Join(errs, errors.Annotatef(err, "pushing %q chart provenance file to %q", id, target))
+					s.markIncomplete(t, ch)
+					continue
+				}
+			}
+		}
+		if len(targetArtifacts) > 0 {
+			if w, ok := dst.(client.ArtifactsWriter); ok {
+				if err := w.PushArtifacts(ch.TargetName, ch.TargetVersion, targetArtifacts); err != nil {
+					klog.Errorf("unable to attach %q chart artifacts in %q: %+v", id, target, err)
+					errs = goerrors.Join(errs, errors.Annotatef(err, "attaching %q chart artifacts in %q", id, target))
+					s.markIncomplete(t, ch)
+					continue
+				}
+			}
+		}
+		// Relocated charts have a different digest than the source ones, so
+		// they are signed again with the target key
+		signed, err := s.signChart(t, ch, dst)
+		if err != nil {
+			klog.Errorf("unable to sign %q chart in %q: %+v", id, target, err)
+			errs = goerrors.Join(errs, errors.Annotatef(err, "signing %q chart in %q", id, target))
+			s.markIncomplete(t, ch)
+			continue
+		}
+		// Hooks can only annotate the charts at this point, but if they fail
+		// the chart is not added to the charts index of the target
+		if err := s.runChartHooks(hook.AfterUnwrap, ch, wrappedChartPath, target); err != nil {
+			klog.Errorf("unable to complete %q chart sync to %q: %+v", id, target, err)
+			errs = goerrors.Join(errs, errors.Trace(err))
+			s.markIncomplete(t, ch)
+			continue
+		}
+		s.clearIncomplete(t, ch)
+		s.markSynced(t, ch)
+		s.addToReport(t, ch, targetReported, signed)
+	}
+	// Keep the wrap until the chart is pushed to every target
+	if prewrappedPath != "" && errs == nil && !s.dryRun {
+		if err := os.Remove(prewrappedPath); err != nil {
+			klog.Warningf("unable to remove %q chart wrapped by a previous sync: %v", id, err)
+		}
+	}
+	return errors.Trace(errs)
+}
+
+// chartUnchanged returns whether the chart pushed to the i-th target is
+// byte-identical to the source chart, so the provenance files and signatures
+// made over the source chart still verify it. Charts change when their images
+// are relocated, or when they are renamed or transformed.
+func (s *Syncer) chartUnchanged(i int, ch *Chart, dst client.ChartsReader) bool {
+	if ch.TargetName != ch.Name || ch.TargetVersion != ch.Version || s.transform != nil {
+		return false
+	}
+	// LOCAL targets store the source chart as is, it is relocated when it is
+	// unwrapped from them
+	if s.targets[i].GetRepo().GetKind() == api.Kind_LOCAL {
+		return true
 	}
-	return nil
+	details, err := dst.GetChartDetails(ch.TargetName, ch.TargetVersion)
+	if err != nil {
+		klog.V(3).Infof("unable to get %q chart digest in %q: %v", ch.id(), s.targetID(i), err)
+		return false
+	}
+	digest, err := utils.FileDigest(ch.TgzPath)
+	if err != nil {
+		klog.V(3).Infof("unable to get %q chart digest: %v", ch.id(), err)
+		return false
+	}
+	return strings.TrimPrefix(details.Digest, "sha256:") == strings.TrimPrefix(digest, "sha256:")
+}
+
+// splitSignatures splits the signatures and attestations of a chart, which
+// are only valid for the source chart, from the rest of its artifacts
+func splitSignatures(artifacts []*types.Artifact) ([]*types.Artifact, []*types.Artifact) {
+	var kept, signatures []*types.Artifact
+	for _, a := range artifacts {
+		switch a.Kind {
+		case types.ArtifactKindSignature, types.ArtifactKindAttestation:
+			signatures = append(signatures, a)
+		default:
+			kept = append(kept, a)
+		}
+	}
+	return kept, signatures
+}
+
+// logImages logs the container images synced along with a wrapped chart
+func (s *Syncer) logImages(ch *Chart, bundle string) {
+	lock, err := chartwrap.ReadImagesLock(bundle)
+	if err != nil {
+		klog.Warningf("unable to read %q chart images: %v", ch.id(), err)
+		return
+	}
+	images := lock.Images.Dedup()
+	if len(images) == 0 {
+		klog.Infof("dry-run: %q chart has no images to sync", ch.id())
+		return
+	}
+	refs := make([]string, len(images))
+	for i, img := range images {
+		refs[i] = img.Image
+	}
+	klog.Infof("dry-run: Syncing %d images with %q chart: %s", len(refs), ch.id(), strings.Join(refs, ", "))
+}
+
+// sourceID returns a human readable identifier for the i-th source
+func (s *Syncer) sourceID(i int) string {
+	repo := s.sources[i].GetRepo()
+	if repo.GetKind() == api.Kind_LOCAL {
+		return repo.GetPath()
+	}
+	return repo.GetUrl()
+}
+
+// targetID returns a human readable identifier for the i-th target
+func (s *Syncer) targetID(i int) string {
+	repo := s.targets[i].GetRepo()
+	if repo.GetKind() == api.Kind_LOCAL {
+		return repo.GetPath()
+	}
+	return repo.GetUrl()
 }
 
 // SyncPendingCharts syncs the charts not found in the target
 func (s *Syncer) SyncPendingCharts(names ...string) error {
 	var errs error
 
+	if len(s.hooks) > 0 {
+		annotations, err := s.runHooks(&hook.Event{Stage: hook.BeforeIndex, Charts: names})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.annotateReport(annotations)
+	}
+
 	// There might be problems loading all the charts due to
 	// invalid/wrong charts in the repository, etc. Therefore, let's warn about
 	// them instead of blocking the whole sync.
 	if err := s.logger.ExecuteStep("Loading charts", func() error {
 		return s.loadCharts(names...)
-	}); err != nil {
+	}); goerrors.Is(err, ErrChartConflict) {
+		return errors.Trace(err)
+	} else if err != nil {
 		s.logger.Warnf("There were some problems loading the information of the requested charts: %v", err)
 		errs = goerrors.Join(errs, errors.Trace(err))
 	} else {
@@ -94,13 +352,36 @@ func (s *Syncer) SyncPendingCharts(names ...string) error {
 		msg = fmt.Sprintf("There is %d chart out of sync!", len(charts))
 	} else {
 		klog.Info("There are no charts out of sync!")
+		if err := s.runEndOfRunHooks(); err != nil {
+			return errors.Trace(err)
+		}
 		return ErrNoChartsToSync
 	}
 
 	klog.Info(msg)
 
+	open, next, err := s.pushWindow(time.Now())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !open && s.dryRun {
+		klog.Infof("dry-run: Outside the allowed windows, the charts would be wrapped and pushed in the next one")
+	} else if !open {
+		if err := s.deferCharts(charts, next); err != nil {
+			errs = goerrors.Join(errs, errors.Trace(err))
+		}
+		s.logger.Warnf("Outside the allowed windows, %d charts were wrapped to be pushed in the next one%s", len(s.report.Deferred), describeNextWindow(next))
+		if err := s.runEndOfRunHooks(); err != nil {
+			errs = goerrors.Join(errs, errors.Trace(err))
+		}
+		if errs != nil {
+			return errors.Trace(errs)
+		}
+		return ErrOutsideWindows
+	}
+
 	for i, ch := range charts {
-		id := fmt.Sprintf("%s-%s", ch.Name, ch.Version)
+		id := ch.id()
 		if err := s.logger.Section(fmt.Sprintf("Syncing %q chart (%d/%d)", id, i+1, len(charts)), func(l log.SectionLogger) error {
 			return s.syncChart(ch, l)
 		}); err != nil {
@@ -108,5 +389,16 @@ func (s *Syncer) SyncPendingCharts(names ...string) error {
 			errs = goerrors.Join(errs, errors.Trace(err))
 		}
 	}
+
+	s.logReport()
+
+	if err := s.publishIndexes(); err != nil {
+		s.logger.Warnf("Failed updating the charts indexes: %v", err)
+		errs = goerrors.Join(errs, errors.Trace(err))
+	}
+
+	if err := s.runEndOfRunHooks(); err != nil {
+		errs = goerrors.Join(errs, errors.Trace(err))
+	}
 	return errors.Trace(errs)
 }
@@ -0,0 +1,145 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/sign"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/juju/errors"
+)
+
+// digestReader is a charts reader returning the same digest for every chart
+type digestReader struct {
+	client.ChartsReader
+	digest string
+}
+
+func (r *digestReader) GetChartDetails(string, string) (*types.ChartDetails, error) {
+	return &types.ChartDetails{Digest: r.digest}, nil
+}
+
+func TestChartUnchanged(t *testing.T) {
+	tgz := "../../testdata/apache-7.3.15.tgz"
+	digest, err := utils.FileDigest(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Syncer{targets: []*api.Target{
+		{Repo: &api.Repo{Kind: api.Kind_OCI}},
+		{Repo: &api.Repo{Kind: api.Kind_LOCAL}},
+	}}
+	ch := &Chart{Name: "apache", Version: "7.3.15", TgzPath: tgz, TargetName: "apache", TargetVersion: "7.3.15"}
+
+	if !s.chartUnchanged(0, ch, &digestReader{digest: digest}) {
+		t.Errorf("want a chart with the source digest unchanged")
+	}
+	// Helm indexes record the digests without the algorithm
+	if !s.chartUnchanged(0, ch, &digestReader{digest: strings.TrimPrefix(digest, "sha256:")}) {
+		t.Errorf("want a chart with the source digest unchanged")
+	}
+	if s.chartUnchanged(0, ch, &digestReader{digest: "sha256:0123"}) {
+		t.Errorf("want a relocated chart changed")
+	}
+	if !s.chartUnchanged(1, ch, nil) {
+		t.Errorf("want a chart stored in a LOCAL target unchanged")
+	}
+	renamed := *ch
+	renamed.TargetName = "my-apache"
+	if s.chartUnchanged(1, &renamed, nil) {
+		t.Errorf("want a renamed chart changed")
+	}
+}
+
+func TestSplitSignatures(t *testing.T) {
+	artifacts := []*types.Artifact{
+		{Kind: types.ArtifactKindSignature},
+		{Kind: types.ArtifactKindSBOM},
+		{Kind: types.ArtifactKindAttestation},
+		{Kind: types.ArtifactKindOther},
+	}
+	kept, signatures := splitSignatures(artifacts)
+	if got, want := describeArtifacts(kept), "1 other, 1 sbom"; got != want {
+		t.Errorf("got %q kept artifacts, want %q", got, want)
+	}
+	if got, want := describeArtifacts(signatures), "1 attestation, 1 signature"; got != want {
+		t.Errorf("got %q signatures, want %q", got, want)
+	}
+}
+
+// artifactsReader is a charts reader attaching the same artifacts to every
+// chart
+type artifactsReader struct {
+	client.ChartsReader
+	artifacts []*types.Artifact
+}
+
+func (r *artifactsReader) ListArtifacts(string, string) ([]*types.Artifact, error) {
+	return r.artifacts, nil
+}
+
+func TestCompleted(t *testing.T) {
+	s := &Syncer{
+		workdir: t.TempDir(),
+		targets: []*api.Target{
+			{Repo: &api.Repo{Kind: api.Kind_OCI, Url: "http://first.example.com"}},
+			{Repo: &api.Repo{Kind: api.Kind_OCI, Url: "http://second.example.com"}},
+		},
+		signers: map[int]*sign.Signer{1: nil},
+	}
+	ch := &Chart{Name: "apache", Version: "7.3.15", TargetName: "apache", TargetVersion: "7.3.15"}
+	dst := &artifactsReader{}
+
+	// Charts failing after being pushed are synced again
+	s.markIncomplete(0, ch)
+	if ok, err := s.completed(0, ch, dst); err != nil || ok {
+		t.Errorf("got completed %v (%v), want a chart marked as incomplete synced again", ok, err)
+	}
+	s.clearIncomplete(0, ch)
+	if ok, err := s.completed(0, ch, dst); err != nil || !ok {
+		t.Errorf("got completed %v (%v), want a chart synced completely", ok, err)
+	}
+
+	// Charts not signed in targets signing them are synced again
+	if ok, err := s.completed(1, ch, dst); err != nil || ok {
+		t.Errorf("got completed %v (%v), want an unsigned chart synced again", ok, err)
+	}
+	dst.artifacts = []*types.Artifact{{Kind: types.ArtifactKindSignature}}
+	if ok, err := s.completed(1, ch, dst); err != nil || !ok {
+		t.Errorf("got completed %v (%v), want a signed chart synced completely", ok, err)
+	}
+}
+
+func TestMapChart(t *testing.T) {
+	s := &Syncer{chartMappings: []*api.ChartMapping{{Charts: []string{"apache"}, Name: "web", Path: "apps"}}}
+	// The prefix resolving a conflict between sources is kept
+	for targetName, want := range map[string]string{"apache": "web", "extra-apache": "extra-web"} {
+		if got, path := s.mapChart("apache", targetName); got != want || path != "apps" {
+			t.Errorf("got %q chart in %q, want %q in %q", got, path, want, "apps")
+		}
+	}
+}
+
+func TestCheckMapping(t *testing.T) {
+	s := &Syncer{sources: []*api.Source{
+		{Repo: &api.Repo{Kind: api.Kind_HELM, Url: "https://charts.example.com"}},
+		{Repo: &api.Repo{Kind: api.Kind_HELM, Url: "https://extra.example.com"}},
+	}}
+	mapped := make(map[string]mappedChart)
+	if err := s.checkMapping(mapped, 0, "apache", "web", "apps", []string{"7.3.15", "7.3.16"}); err != nil {
+		t.Fatal(err)
+	}
+	// Different versions, or paths, do not conflict
+	if err := s.checkMapping(mapped, 1, "nginx", "web", "apps", []string{"9.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkMapping(mapped, 1, "apache", "web", "", []string{"7.3.15"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkMapping(mapped, 1, "apache", "web", "apps", []string{"7.3.16"}); !errors.Is(err, ErrChartConflict) {
+		t.Errorf("got error %v, want %v", err, ErrChartConflict)
+	}
+}
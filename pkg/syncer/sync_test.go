@@ -1,13 +1,21 @@
 package syncer_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/bitnami/charts-syncer/pkg/gate"
+	"github.com/bitnami/charts-syncer/pkg/hook"
 	"github.com/bitnami/charts-syncer/pkg/syncer"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 func TestFakeSyncPendingCharts(t *testing.T) {
@@ -64,3 +72,348 @@ func TestFakeSyncPendingCharts(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeSyncPendingChartsMultipleTargets(t *testing.T) {
+	dstTmp, err := os.MkdirTemp("", "charts-syncer-tests-dst-fake")
+	if err != nil {
+		t.Fatalf("error creating temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dstTmp)
+
+	// The extra target already contains the kafka chart, so only apache is
+	// expected to be uploaded there. Otherwise, the local target would
+	// complain about the chart already existing.
+	extraDstTmp, err := os.MkdirTemp("", "charts-syncer-tests-dst-fake")
+	if err != nil {
+		t.Fatalf("error creating temporary folder: %v", err)
+	}
+	defer os.RemoveAll(extraDstTmp)
+	input, err := os.ReadFile("../../testdata/kafka-10.3.3.wrap.tgz")
+	if err != nil {
+		t.Fatalf("error reading kafka chart: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(extraDstTmp, "kafka-10.3.3.wrap.tgz"), input, 0644); err != nil {
+		t.Fatalf("error copying kafka chart: %v", err)
+	}
+
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeSyncerExtraDestination(extraDstTmp))
+	if err := s.SyncPendingCharts("apache", "kafka"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"apache-7.3.15.wrap.tgz", "kafka-10.3.3.wrap.tgz"}
+	for _, dir := range []string{dstTmp, extraDstTmp} {
+		gotFiles, err := filepath.Glob(fmt.Sprintf("%s/*.tgz", dir))
+		if err != nil {
+			t.Fatalf("error listing tgz files: %v", err)
+		}
+
+		var got []string
+		for _, file := range gotFiles {
+			got = append(got, filepath.Base(file))
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got: %v, want: %v\n", dir, got, want)
+		}
+	}
+}
+
+func TestFakeSyncPendingChartsMultipleSources(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		policy  api.ConflictPolicy
+		want    []string
+		wantErr bool
+	}{
+		{
+			desc:   "prefer first source",
+			policy: api.ConflictPolicy_PREFER_FIRST,
+			want:   []string{"apache-7.3.15.wrap.tgz", "kafka-10.3.3.wrap.tgz"},
+		},
+		{
+			desc:   "prefix conflicting charts",
+			policy: api.ConflictPolicy_PREFIX,
+			want:   []string{"apache-7.3.15.wrap.tgz", "extra-apache-7.3.15.wrap.tgz", "kafka-10.3.3.wrap.tgz"},
+		},
+		{
+			desc:    "fail on conflicting charts",
+			policy:  api.ConflictPolicy_FAIL,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dstTmp := t.TempDir()
+
+			// The extra source publishes apache too
+			extraSrcTmp := t.TempDir()
+			input, err := os.ReadFile("../../testdata/apache-7.3.15.wrap.tgz")
+			if err != nil {
+				t.Fatalf("error reading apache chart: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(extraSrcTmp, "apache-7.3.15.wrap.tgz"), input, 0644); err != nil {
+				t.Fatalf("error copying apache chart: %v", err)
+			}
+
+			s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp),
+				syncer.WithFakeSyncerExtraSource(extraSrcTmp, "extra-"), syncer.WithFakeConflictPolicy(tc.policy))
+			err = s.SyncPendingCharts("apache", "kafka")
+			if tc.wantErr {
+				if !errors.Is(err, syncer.ErrChartConflict) {
+					t.Fatalf("got error %v, want %v", err, syncer.ErrChartConflict)
+				}
+				// Nothing should have been synced
+				if gotFiles, _ := filepath.Glob(fmt.Sprintf("%s/*.tgz", dstTmp)); len(gotFiles) > 0 {
+					t.Errorf("got %v synced charts, want none", gotFiles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotFiles, err := filepath.Glob(fmt.Sprintf("%s/*.tgz", dstTmp))
+			if err != nil {
+				t.Fatalf("error listing tgz files: %v", err)
+			}
+
+			var got []string
+			for _, file := range gotFiles {
+				got = append(got, filepath.Base(file))
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got: %v, want: %v\n", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFakeSyncPendingChartsMappings(t *testing.T) {
+	dstTmp := t.TempDir()
+
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeChartMappings(
+		&api.ChartMapping{Charts: []string{"kafka"}, Name: "events", Path: "apps"},
+		&api.ChartMapping{Charts: []string{"kafka", "apache"}, Prefix: "bitnami-", Path: "web"},
+	))
+	if err := s.SyncPendingCharts("apache", "kafka"); err != nil {
+		t.Fatal(err)
+	}
+
+	gotFiles, err := filepath.Glob(fmt.Sprintf("%s/*/*.tgz", dstTmp))
+	if err != nil {
+		t.Fatalf("error listing tgz files: %v", err)
+	}
+	var got []string
+	for _, file := range gotFiles {
+		rel, _ := filepath.Rel(dstTmp, file)
+		got = append(got, rel)
+	}
+	want := []string{"apps/events-10.3.3.wrap.tgz", "web/bitnami-apache-7.3.15.wrap.tgz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v\n", got, want)
+	}
+
+	// The mapped charts are already in the destination
+	s = syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeChartMappings(
+		&api.ChartMapping{Charts: []string{"kafka"}, Name: "events", Path: "apps"},
+		&api.ChartMapping{Charts: []string{"kafka", "apache"}, Prefix: "bitnami-", Path: "web"},
+	))
+	if err := s.SyncPendingCharts("apache", "kafka"); !errors.Is(err, syncer.ErrNoChartsToSync) {
+		t.Errorf("got error %v, want %v", err, syncer.ErrNoChartsToSync)
+	}
+}
+
+// fakeGate decides about the charts by name
+type fakeGate map[string]*gate.Result
+
+func (g fakeGate) Check(_ context.Context, req *gate.Request) (*gate.Result, error) {
+	if res, ok := g[req.Chart.Name]; ok {
+		return res, nil
+	}
+	return &gate.Result{Decision: gate.Allow}, nil
+}
+
+func TestFakeSyncPendingChartsGates(t *testing.T) {
+	dstTmp := t.TempDir()
+
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeGates(fakeGate{
+		"apache": {Decision: gate.Deny, Reason: "1 vulnerability with HIGH or higher severity: CVE-2024-0001"},
+		"kafka":  {Decision: gate.Warn, Reason: "1 vulnerability with HIGH or higher severity: CVE-2024-0002"},
+	}))
+	if err := s.SyncPendingCharts("apache", "kafka", "zookeeper"); !errors.Is(err, syncer.ErrChartDenied) {
+		t.Errorf("got error %v, want %v", err, syncer.ErrChartDenied)
+	}
+
+	gotFiles, err := filepath.Glob(fmt.Sprintf("%s/*.tgz", dstTmp))
+	if err != nil {
+		t.Fatalf("error listing tgz files: %v", err)
+	}
+	var got []string
+	for _, file := range gotFiles {
+		got = append(got, filepath.Base(file))
+	}
+	want := []string{"kafka-10.3.3.wrap.tgz", "zookeeper-5.14.3.wrap.tgz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v, want: %v\n", got, want)
+	}
+
+	warnings := map[string][]string{}
+	for _, c := range s.Report().Charts {
+		warnings[c.Name] = c.Warnings
+	}
+	wantWarnings := map[string][]string{
+		"kafka":     {"1 vulnerability with HIGH or higher severity: CVE-2024-0002"},
+		"zookeeper": nil,
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("got warnings: %v, want: %v\n", warnings, wantWarnings)
+	}
+}
+
+func TestFakeSyncPendingChartsHooks(t *testing.T) {
+	dstTmp := t.TempDir()
+
+	var stages []hook.Stage
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeHooks(hook.Func(func(_ context.Context, ev *hook.Event) (*hook.Result, error) {
+		stages = append(stages, ev.Stage)
+		switch {
+		case ev.Stage == hook.BeforeIndex:
+			return &hook.Result{Annotations: map[string]string{"change": "CHG-1234"}}, nil
+		case ev.Stage == hook.AfterFetch && ev.Chart.Name == "apache":
+			return &hook.Result{Veto: true, Reason: "not approved"}, nil
+		case ev.Stage == hook.AfterWrap:
+			return &hook.Result{Annotations: map[string]string{"ticket": "TICKET-" + ev.Chart.Name}}, nil
+		case ev.Stage == hook.AfterUnwrap:
+			// Published charts cannot be vetoed
+			return &hook.Result{Veto: true, Reason: "too late", Annotations: map[string]string{"published": "true"}}, nil
+		case ev.Stage == hook.EndOfRun:
+			return &hook.Result{Veto: true, Annotations: map[string]string{"synced": fmt.Sprint(len(ev.Report.(*syncer.Report).Charts))}}, nil
+		}
+		return nil, nil
+	})))
+	if err := s.SyncPendingCharts("apache", "kafka"); !errors.Is(err, syncer.ErrVetoed) {
+		t.Errorf("got error %v, want %v", err, syncer.ErrVetoed)
+	}
+
+	wantStages := []hook.Stage{hook.BeforeIndex, hook.AfterFetch, hook.AfterFetch, hook.AfterWrap, hook.AfterUnwrap, hook.EndOfRun}
+	if !reflect.DeepEqual(stages, wantStages) {
+		t.Errorf("got stages: %v, want: %v\n", stages, wantStages)
+	}
+
+	report := s.Report()
+	if len(report.Charts) != 1 || report.Charts[0].Name != "kafka" {
+		t.Fatalf("got synced charts: %+v, want only kafka", report.Charts)
+	}
+	if want := map[string]string{"ticket": "TICKET-kafka", "published": "true"}; !reflect.DeepEqual(report.Charts[0].Annotations, want) {
+		t.Errorf("got chart annotations: %v, want: %v\n", report.Charts[0].Annotations, want)
+	}
+	if want := map[string]string{"change": "CHG-1234", "synced": "1"}; !reflect.DeepEqual(report.Annotations, want) {
+		t.Errorf("got report annotations: %v, want: %v\n", report.Annotations, want)
+	}
+}
+
+func TestFakeSyncPendingChartsAllowedWindows(t *testing.T) {
+	dstTmp := t.TempDir()
+	workdir := t.TempDir()
+	// February 30th never comes
+	closed := &api.AllowedWindow{Schedule: "0 0 30 2 *", Duration: "1h"}
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeSyncerWorkdir(workdir), syncer.WithFakeAllowedWindows(closed))
+
+	// A wrap of a different source chart is stale
+	stale := filepath.Join(workdir, "prewrapped", "0123456789ab", "kafka-10.3.3.wrap.tgz")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SyncPendingCharts("kafka"); !errors.Is(err, syncer.ErrOutsideWindows) {
+		t.Fatalf("got error %v, want %v", err, syncer.ErrOutsideWindows)
+	}
+	if report := s.Report(); len(report.Charts) != 0 || len(report.Deferred) != 1 || report.Deferred[0].Name != "kafka" {
+		t.Fatalf("got synced charts %+v and deferred charts %+v, want kafka deferred", report.Charts, report.Deferred)
+	}
+	if _, err := os.Stat(filepath.Join(dstTmp, "kafka-10.3.3.wrap.tgz")); !os.IsNotExist(err) {
+		t.Fatalf("want kafka not pushed outside the allowed windows, got %v", err)
+	}
+	prewrapped, err := filepath.Glob(filepath.Join(workdir, "prewrapped", "*", "kafka-10.3.3.wrap.tgz"))
+	if err != nil || len(prewrapped) != 1 || prewrapped[0] == stale {
+		t.Fatalf("want kafka wrapped into the workdir and the stale wrap removed, got %v: %v", prewrapped, err)
+	}
+
+	// The window is open every minute
+	syncer.WithAllowedWindows(&api.AllowedWindow{Schedule: "* * * * *", Duration: "1m"})(s)
+	if err := s.SyncPendingCharts("kafka"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dstTmp, "kafka-10.3.3.wrap.tgz")); err != nil {
+		t.Fatalf("want kafka pushed inside the allowed windows, got %v", err)
+	}
+	if _, err := os.Stat(prewrapped[0]); !os.IsNotExist(err) {
+		t.Fatalf("want the kafka wrap removed once pushed, got %v", err)
+	}
+}
+
+func TestFakeSyncPendingChartsTransform(t *testing.T) {
+	dstTmp := t.TempDir()
+	transform := &api.Transform{
+		ImageRegistry:    "registry.example.com",
+		ImagePullSecrets: []string{"regcred"},
+		Annotations:      map[string]string{"example.com/team": "platform"},
+		AnnotateOrigin:   true,
+		Values:           []*api.ValuePatch{{Path: "$.metrics.kafka.enabled", Value: "true"}},
+		VersionSuffix:    "+mirror",
+	}
+	s := syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeTransform(transform))
+	if err := s.SyncPendingCharts("kafka"); err != nil {
+		t.Fatal(err)
+	}
+
+	if report := s.Report(); len(report.Charts) != 1 || report.Charts[0].Version != "10.3.3+mirror" {
+		t.Fatalf("got synced charts: %+v, want kafka 10.3.3+mirror", report.Charts)
+	}
+	bundle := filepath.Join(dstTmp, "kafka-10.3.3+mirror.wrap.tgz")
+	if err := chartwrap.Rewrite(bundle, filepath.Join(t.TempDir(), "kafka.wrap.tgz"), func(w wrapping.Wrap) error {
+		c, err := loader.LoadDir(w.ChartDir())
+		if err != nil {
+			return err
+		}
+		if got, want := c.Metadata.Version, "10.3.3+mirror"; got != want {
+			t.Errorf("got chart version %q, want %q", got, want)
+		}
+		if got, want := c.Metadata.Annotations["example.com/team"], "platform"; got != want {
+			t.Errorf("got team annotation %q, want %q", got, want)
+		}
+		if got := c.Metadata.Annotations[syncer.MirroredFromAnnotation]; got == "" {
+			t.Errorf("missing %q annotation", syncer.MirroredFromAnnotation)
+		}
+		if got := c.Metadata.Annotations[syncer.SyncedAtAnnotation]; got == "" {
+			t.Errorf("missing %q annotation", syncer.SyncedAtAnnotation)
+		}
+		global, _ := c.Values["global"].(map[string]interface{})
+		if got, want := global["imageRegistry"], "registry.example.com"; got != want {
+			t.Errorf("got global.imageRegistry %v, want %q", got, want)
+		}
+		if got, want := fmt.Sprint(global["imagePullSecrets"]), "[regcred]"; got != want {
+			t.Errorf("got global.imagePullSecrets %v, want %v", got, want)
+		}
+		metrics, _ := c.Values["metrics"].(map[string]interface{})
+		kafka, _ := metrics["kafka"].(map[string]interface{})
+		if got := kafka["enabled"]; got != true {
+			t.Errorf("got metrics.kafka.enabled %v, want true", got)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The suffixed version is found in the target in later runs
+	s = syncer.NewFake(t, syncer.WithFakeSyncerDestination(dstTmp), syncer.WithFakeTransform(transform))
+	if err := s.SyncPendingCharts("kafka"); !errors.Is(err, syncer.ErrNoChartsToSync) {
+		t.Errorf("got error %v, want %v", err, syncer.ErrNoChartsToSync)
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"os"
 
 	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/sign"
+	"github.com/bitnami/charts-syncer/internal/verify"
 	"github.com/bitnami/charts-syncer/pkg/client"
 	cs "github.com/bitnami/charts-syncer/pkg/client/source"
 	ct "github.com/bitnami/charts-syncer/pkg/client/target"
+	"github.com/bitnami/charts-syncer/pkg/gate"
+	"github.com/bitnami/charts-syncer/pkg/hook"
 
 	"github.com/bitnami/charts-syncer/pkg/client/types"
 	"github.com/juju/errors"
@@ -19,14 +23,24 @@ import (
 
 // Clients holds the source and target chart repo clients
 type Clients struct {
-	src client.ChartsWrapper
-	dst client.ChartsUnwrapper
+	// src contains a client per source, in the same order as Syncer.sources
+	src []client.ChartsWrapper
+	// dst contains a client per target, in the same order as Syncer.targets
+	dst []client.ChartsUnwrapper
+	// dstPaths contains the clients for the target sub-paths used by the
+	// chart mappings, indexed by "<target index>/<path>"
+	dstPaths map[string]client.ChartsUnwrapper
 }
 
 // A Syncer can be used to sync a source and target chart repos.
 type Syncer struct {
-	source *api.Source
-	target *api.Target
+	sources []*api.Source
+	targets []*api.Target
+
+	// how to handle charts published by several sources
+	conflictPolicy api.ConflictPolicy
+	// rules to rename and place charts in the targets
+	chartMappings []*api.ChartMapping
 
 	cli *Clients
 
@@ -41,13 +55,36 @@ type Syncer struct {
 
 	// list of container platforms to sync
 	containerPlatforms []string
+	// methods to find the images of the charts without an images annotation
+	imageDiscovery []api.ImageDiscovery
 	// TODO(jdrios): Cache index in local filesystem to speed
 	// up re-runs
 	index ChartIndex
+	// charts synced into each target, indexed by the target index
+	synced map[int][]*Chart
+	// report of the charts synced by the run
+	report Report
 
 	// skip syncing artifacts
 	skipArtifacts bool
 
+	// how to verify the source charts, and the keys to do it
+	verification *api.Verification
+	keyring      *verify.Keyring
+	publicKey    *verify.PublicKey
+
+	// gates checking the wrapped charts before publishing them
+	gates []gate.Gate
+	// hooks run at the different stages of the sync
+	hooks []hook.Hook
+	// changes applied to the charts before publishing them
+	transform *api.Transform
+	// windows when the charts can be pushed to the targets
+	allowedWindows []*api.AllowedWindow
+
+	// keys signing the charts pushed to each target, indexed by the target index
+	signers map[int]*sign.Signer
+
 	// Storage directory for required artifacts
 	workdir string
 
@@ -122,13 +159,48 @@ func WithLatestVersionOnly(latestVersionOnly bool) Option {
 	}
 }
 
+// WithTargets configures additional targets to sync into. The charts are
+// wrapped once and unwrapped into every target that is missing them.
+func WithTargets(targets ...*api.Target) Option {
+	return func(s *Syncer) {
+		s.targets = append(s.targets, targets...)
+	}
+}
+
+// WithSources configures additional sources to sync from.
+func WithSources(sources ...*api.Source) Option {
+	return func(s *Syncer) {
+		s.sources = append(s.sources, sources...)
+	}
+}
+
+// WithConflictPolicy configures how to handle charts with the same name
+// published by several sources.
+func WithConflictPolicy(p api.ConflictPolicy) Option {
+	return func(s *Syncer) {
+		s.conflictPolicy = p
+	}
+}
+
+// WithChartMappings configures the rules to rename charts and choose where
+// they are stored in the targets. The first rule matching a chart is applied.
+func WithChartMappings(mappings ...*api.ChartMapping) Option {
+	return func(s *Syncer) {
+		s.chartMappings = append(s.chartMappings, mappings...)
+	}
+}
+
 // New creates a new syncer using Client
 func New(source *api.Source, target *api.Target, opts ...Option) (*Syncer, error) {
 	s := &Syncer{
-		source: source,
-		target: target,
 		logger: silent.NewSectionLogger(),
 	}
+	if source != nil {
+		s.sources = []*api.Source{source}
+	}
+	if target != nil {
+		s.targets = []*api.Target{target}
+	}
 
 	for _, o := range opts {
 		o(s)
@@ -145,25 +217,40 @@ func New(source *api.Source, target *api.Target, opts ...Option) (*Syncer, error
 		return nil, errors.Trace(err)
 	}
 
+	if err := s.loadVerifiers(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := s.loadSigners(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	s.cli = &Clients{}
-	if source.GetRepo() != nil {
-		srcCli, err := cs.NewClient(source, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
+	if len(s.sources) == 0 {
+		return nil, errors.New("no source info defined in config file")
+	}
+	for _, src := range s.sources {
+		if src.GetRepo() == nil {
+			return nil, errors.New("no source info defined in config file")
+		}
+		srcCli, err := cs.NewClient(src, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		s.cli.src = srcCli
-	} else {
-		return nil, errors.New("no source info defined in config file")
+		s.cli.src = append(s.cli.src, srcCli)
 	}
 
-	if target.GetRepo() != nil {
-		dstCli, err := ct.NewClient(target, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
+	if len(s.targets) == 0 {
+		return nil, errors.New("no target info defined in config file")
+	}
+	for _, t := range s.targets {
+		if t.GetRepo() == nil {
+			return nil, errors.New("no target info defined in config file")
+		}
+		dstCli, err := ct.NewClient(t, types.WithCache(s.workdir), types.WithInsecure(s.insecure), types.WithUsePlainHTTP(s.usePlainHTTP))
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		s.cli.dst = dstCli
-	} else {
-		return nil, errors.New("no target info defined in config file")
+		s.cli.dst = append(s.cli.dst, dstCli)
 	}
 
 	return s, nil
@@ -184,3 +271,12 @@ func WithContainerPlatforms(platforms []string) Option {
 		s.containerPlatforms = platforms
 	}
 }
+
+// WithImageDiscovery configures the syncer to look for the container images of
+// the charts not listing them in their "images" annotation, so they are synced
+// too.
+func WithImageDiscovery(methods ...api.ImageDiscovery) Option {
+	return func(s *Syncer) {
+		s.imageDiscovery = methods
+	}
+}
@@ -0,0 +1,113 @@
+package syncer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/chartwrap"
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/wrapping"
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog"
+)
+
+const (
+	// MirroredFromAnnotation is the chart annotation with the source the chart
+	// was synced from
+	MirroredFromAnnotation = "charts-syncer.bitnami.com/mirrored-from"
+	// SyncedAtAnnotation is the chart annotation with the time the chart was
+	// synced
+	SyncedAtAnnotation = "charts-syncer.bitnami.com/synced-at"
+)
+
+// WithTransform configures the syncer to change the values and metadata of
+// the charts before publishing them
+func WithTransform(t *api.Transform) Option {
+	return func(s *Syncer) {
+		s.transform = t
+	}
+}
+
+// transformChart applies the configured transform to a wrapped chart
+func (s *Syncer) transformChart(w wrapping.Wrap, ch *Chart) error {
+	t := s.transform
+	if t == nil {
+		return nil
+	}
+	id := ch.id()
+
+	var values []chartwrap.Value
+	if r := t.GetImageRegistry(); r != "" {
+		values = append(values, chartwrap.Value{Path: "global.imageRegistry", Value: r})
+	}
+	if secrets := t.GetImagePullSecrets(); len(secrets) > 0 {
+		values = append(values, chartwrap.Value{Path: "global.imagePullSecrets", Value: secrets})
+	}
+	for _, p := range t.GetValues() {
+		var v interface{}
+		if err := yaml.Unmarshal([]byte(p.GetValue()), &v); err != nil {
+			return errors.Annotatef(err, "decoding %q value", p.GetPath())
+		}
+		values = append(values, chartwrap.Value{Path: p.GetPath(), Value: v})
+	}
+	if len(values) > 0 {
+		klog.V(3).Infof("Setting %d values in %q chart...", len(values), id)
+		if err := chartwrap.SetValues(w, values); err != nil {
+			return errors.Annotatef(err, "unable to set %q chart values", id)
+		}
+	}
+
+	annotations := make(map[string]string, len(t.GetAnnotations())+2)
+	for k, v := range t.GetAnnotations() {
+		annotations[k] = v
+	}
+	if t.GetAnnotateOrigin() {
+		annotations[MirroredFromAnnotation] = s.sourceID(ch.Source)
+		annotations[SyncedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(annotations) > 0 {
+		klog.V(3).Infof("Annotating %q chart...", id)
+		if err := chartwrap.SetAnnotations(w, annotations); err != nil {
+			return errors.Annotatef(err, "unable to annotate %q chart", id)
+		}
+	}
+
+	if ch.TargetVersion != ch.Version {
+		klog.V(3).Infof("Changing %q chart version to %q...", ch.Name, ch.TargetVersion)
+		if err := chartwrap.SetVersion(w, ch.TargetVersion); err != nil {
+			return errors.Annotatef(err, "unable to change %q chart version to %q", ch.Name, ch.TargetVersion)
+		}
+	}
+	return nil
+}
+
+// suffixVersion appends a pre-release or build suffix, like "-mirror" or
+// "+mirror", to a semver version. Versions already having a pre-release or
+// build part are extended with dot-separated identifiers, so the result is
+// still a valid version, i.e "1.0.0-rc.1" becomes "1.0.0-rc.1.mirror".
+func suffixVersion(version, suffix string) string {
+	if suffix == "" {
+		return version
+	}
+	pre, build, _ := strings.Cut(suffix, "+")
+	pre = strings.TrimPrefix(pre, "-")
+
+	v, vbuild, _ := strings.Cut(version, "+")
+	if pre != "" {
+		if strings.Contains(v, "-") {
+			v += "." + pre
+		} else {
+			v += "-" + pre
+		}
+	}
+	switch {
+	case vbuild != "" && build != "":
+		v += "+" + vbuild + "." + build
+	case vbuild != "":
+		v += "+" + vbuild
+	case build != "":
+		v += "+" + build
+	}
+	return v
+}
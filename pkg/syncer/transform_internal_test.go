@@ -0,0 +1,24 @@
+package syncer
+
+import "testing"
+
+func TestSuffixVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		suffix  string
+		want    string
+	}{
+		{"1.0.0", "", "1.0.0"},
+		{"1.0.0", "-mirror", "1.0.0-mirror"},
+		{"1.0.0", "+mirror", "1.0.0+mirror"},
+		{"1.0.0-rc.1", "-mirror", "1.0.0-rc.1.mirror"},
+		{"1.0.0+build.1", "+mirror", "1.0.0+build.1.mirror"},
+		{"1.0.0+build.1", "-mirror", "1.0.0-mirror+build.1"},
+		{"1.0.0-rc.1+build.1", "-mirror+2", "1.0.0-rc.1.mirror+build.1.2"},
+	}
+	for _, tc := range testCases {
+		if got := suffixVersion(tc.version, tc.suffix); got != tc.want {
+			t.Errorf("suffixVersion(%q, %q) = %q, want %q", tc.version, tc.suffix, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package syncer
+
+import (
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/internal/verify"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/juju/errors"
+	"k8s.io/klog"
+)
+
+// ErrUnverifiedChart is returned when a chart fails verification and the
+// verification policy rejects it
+var ErrUnverifiedChart = errors.New("chart failed verification")
+
+// WithVerification configures the syncer to verify the source charts before
+// syncing them
+func WithVerification(v *api.Verification) Option {
+	return func(s *Syncer) {
+		s.verification = v
+	}
+}
+
+// loadVerifiers loads the keys of the verification policy
+func (s *Syncer) loadVerifiers() error {
+	var err error
+	if k := s.verification.GetKeyring(); k != "" {
+		if s.keyring, err = verify.NewKeyring(k); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if k := s.verification.GetPublicKey(); k != "" {
+		if s.publicKey, err = verify.LoadPublicKey(k); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// verifyChart verifies a fetched chart with the verification policy. Charts
+// failing verification, or that can not be verified, are rejected, or flagged
+// if the policy allows it.
+func (s *Syncer) verifyChart(ch *Chart) error {
+	if s.verification == nil {
+		return nil
+	}
+	err := s.verifySignature(ch)
+	if err == nil {
+		return nil
+	}
+	if s.verification.GetPolicy() == api.VerificationPolicy_FLAG {
+		klog.Warningf("%q chart failed verification: %v", ch.id(), err)
+		ch.Unverified = err.Error()
+		return nil
+	}
+	return errors.Annotatef(ErrUnverifiedChart, "%q: %v", ch.id(), err)
+}
+
+// verifySignature verifies the provenance file or the signatures of a chart,
+// depending on its source kind. An error is returned for the charts from
+// sources without keys to verify them.
+func (s *Syncer) verifySignature(ch *Chart) error {
+	src := s.cli.src[ch.Source]
+	kind := s.sources[ch.Source].GetRepo().GetKind()
+	switch kind {
+	case api.Kind_HELM, api.Kind_CHARTMUSEUM, api.Kind_HARBOR:
+		if s.keyring == nil {
+			return errors.Errorf("no keyring to verify the provenance files of %s sources", kind)
+		}
+		if ch.ProvPath == "" {
+			return errors.NotFoundf("%s:%s provenance file", ch.Name, ch.Version)
+		}
+		return errors.Trace(s.keyring.Verify(ch.TgzPath, ch.ProvPath))
+	case api.Kind_OCI:
+		if s.publicKey == nil {
+			return errors.Errorf("no publicKey to verify the signatures of %s sources", kind)
+		}
+		r, ok := src.(client.ArtifactsReader)
+		if !ok {
+			return errors.NotSupportedf("signatures")
+		}
+		artifacts, err := r.ListArtifacts(ch.Name, ch.Version)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var subject, chartDigest string
+		var signatures []v1.Image
+		for _, a := range artifacts {
+			if a.Kind == types.ArtifactKindSignature {
+				subject, chartDigest = a.Subject, a.ChartDigest
+				signatures = append(signatures, a.Image)
+			}
+		}
+		if len(signatures) == 0 {
+			return errors.NotFoundf("signatures")
+		}
+		if err := s.publicKey.Verify(subject, signatures); err != nil {
+			return errors.Trace(err)
+		}
+		// The fetched chart may come from the cache or from a tag moved since,
+		// so it must be the one of the signed manifest
+		digest, err := utils.FileDigest(ch.TgzPath)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if digest != chartDigest {
+			return errors.Errorf("chart digest %s does not match the one of the signed manifest %s: %s", digest, subject, chartDigest)
+		}
+		return nil
+	}
+	return errors.NotSupportedf("verifying the charts of %s sources", kind)
+}
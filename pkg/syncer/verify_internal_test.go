@@ -0,0 +1,115 @@
+package syncer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/sign"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client"
+	"github.com/bitnami/charts-syncer/pkg/client/types"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/juju/errors"
+)
+
+// signedReader is a charts reader returning a signature of the same manifest
+// for every chart
+type signedReader struct {
+	client.ChartsWrapper
+	artifact *types.Artifact
+}
+
+func (r *signedReader) ListArtifacts(string, string) ([]*types.Artifact, error) {
+	return []*types.Artifact{r.artifact}, nil
+}
+
+func TestVerifyChart(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDer, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privFile, pubFile := filepath.Join(dir, "cosign.key"), filepath.Join(dir, "cosign.pub")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := sign.LoadPrivateKey(privFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const subject = "sha256:4a5f9c2f3d8e1b0a6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b"
+	sig, err := signer.Sign(empty.Image, "registry.example.com/charts/apache", subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tgz := "../../testdata/apache-7.3.15.tgz"
+	digest, err := utils.FileDigest(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := func(chartDigest string) client.ChartsWrapper {
+		return &signedReader{artifact: &types.Artifact{Kind: types.ArtifactKindSignature, Subject: subject, ChartDigest: chartDigest, Image: sig}}
+	}
+
+	tests := []struct {
+		desc           string
+		kind           api.Kind
+		src            client.ChartsWrapper
+		verification   *api.Verification
+		wantRejected   bool
+		wantUnverified bool
+	}{
+		{desc: "signed chart", kind: api.Kind_OCI, src: signed(digest), verification: &api.Verification{PublicKey: pubFile}},
+		{desc: "cached chart not matching the signed one", kind: api.Kind_OCI, src: signed("sha256:0000000000000000000000000000000000000000000000000000000000000000"), verification: &api.Verification{PublicKey: pubFile}, wantRejected: true},
+		{desc: "flagged chart not matching the signed one", kind: api.Kind_OCI, src: signed("sha256:0000000000000000000000000000000000000000000000000000000000000000"), verification: &api.Verification{PublicKey: pubFile, Policy: api.VerificationPolicy_FLAG}, wantUnverified: true},
+		{desc: "source without key", kind: api.Kind_HELM, verification: &api.Verification{PublicKey: pubFile}, wantRejected: true},
+		{desc: "flagged source without key", kind: api.Kind_HELM, verification: &api.Verification{PublicKey: pubFile, Policy: api.VerificationPolicy_FLAG}, wantUnverified: true},
+		{desc: "local source", kind: api.Kind_LOCAL, verification: &api.Verification{PublicKey: pubFile}, wantRejected: true},
+		{desc: "no verification", kind: api.Kind_LOCAL},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &Syncer{
+				sources:      []*api.Source{{Repo: &api.Repo{Kind: tc.kind}}},
+				cli:          &Clients{src: []client.ChartsWrapper{tc.src}},
+				verification: tc.verification,
+			}
+			if err := s.loadVerifiers(); err != nil {
+				t.Fatal(err)
+			}
+			ch := &Chart{Name: "apache", Version: "7.3.15", TgzPath: tgz}
+			err := s.verifyChart(ch)
+			if tc.wantRejected {
+				if !errors.Is(err, ErrUnverifiedChart) {
+					t.Errorf("got error %v, want a chart failing verification", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ch.Unverified != ""; got != tc.wantUnverified {
+				t.Errorf("got unverified %v (%q), want %v", got, ch.Unverified, tc.wantUnverified)
+			}
+		})
+	}
+}
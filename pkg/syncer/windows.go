@@ -0,0 +1,181 @@
+package syncer
+
+import (
+	goerrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bitnami/charts-syncer/api"
+	"github.com/bitnami/charts-syncer/internal/schedule"
+	"github.com/bitnami/charts-syncer/internal/throttle"
+	"github.com/bitnami/charts-syncer/internal/utils"
+	"github.com/bitnami/charts-syncer/pkg/client/config"
+	"github.com/juju/errors"
+	"github.com/vmware-labs/distribution-tooling-for-helm/pkg/log"
+	"k8s.io/klog"
+)
+
+// ErrOutsideWindows is returned when the sync runs outside the allowed windows,
+// so the charts are wrapped but not pushed
+var ErrOutsideWindows = goerrors.New("outside the allowed windows")
+
+// WithAllowedWindows configures the windows when the charts can be pushed to
+// the targets. Syncs outside them wrap the charts into the workdir, and a later
+// sync inside a window pushes them.
+func WithAllowedWindows(windows ...*api.AllowedWindow) Option {
+	return func(s *Syncer) {
+		s.allowedWindows = append(s.allowedWindows, windows...)
+	}
+}
+
+// pushWindow returns whether the charts can be pushed at the provided time and,
+// if not, the next time they can. A zero time is returned if no window opens
+// within a year.
+func (s *Syncer) pushWindow(now time.Time) (bool, time.Time, error) {
+	if len(s.allowedWindows) == 0 {
+		return true, time.Time{}, nil
+	}
+	var next time.Time
+	for _, aw := range s.allowedWindows {
+		w, err := schedule.NewWindow(aw.GetSchedule(), aw.GetDuration(), aw.GetTimeZone())
+		if err != nil {
+			return false, time.Time{}, errors.Annotatef(err, "invalid %q window", aw.GetSchedule())
+		}
+		if w.Contains(now) {
+			return true, time.Time{}, nil
+		}
+		if t, ok := w.Next(now); ok && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return false, next, nil
+}
+
+// deferCharts wraps the charts into the workdir, so they are pushed faster by
+// the next sync inside an allowed window
+func (s *Syncer) deferCharts(charts []*Chart, next time.Time) error {
+	var errs error
+	for i, ch := range charts {
+		id := ch.id()
+		if err := s.logger.Section(fmt.Sprintf("Wrapping %q chart (%d/%d)", id, i+1, len(charts)), func(l log.SectionLogger) error {
+			return s.prewrapChart(ch, l)
+		}); err != nil {
+			s.logger.Warnf("Failed wrapping %q chart: %v", id, err)
+			errs = goerrors.Join(errs, errors.Trace(err))
+			continue
+		}
+		s.report.Deferred = append(s.report.Deferred, &DeferredChart{Name: ch.TargetName, Version: ch.TargetVersion})
+	}
+	if !next.IsZero() {
+		s.report.NextWindow = &next
+	}
+	return errs
+}
+
+// describeNextWindow returns a human readable suffix telling when the next
+// window opens, if any
+func describeNextWindow(next time.Time) string {
+	if next.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(", at %s", next.Format(time.RFC3339))
+}
+
+// prewrapPath returns where the chart is wrapped while waiting for an allowed
+// window. Wraps depend on the source chart and the wrap settings, so a change
+// of them does not reuse stale wraps.
+func (s *Syncer) prewrapPath(ch *Chart) (string, error) {
+	digest, err := utils.FileDigest(ch.TgzPath)
+	if err != nil {
+		return "", errors.Annotatef(err, "hashing %q chart", ch.id())
+	}
+	key := utils.EncodeSha1(fmt.Sprintf("%s|%s|%v|%v|%v", s.sourceID(ch.Source), digest, s.containerPlatforms, s.skipArtifacts, s.imageDiscovery))
+	return filepath.Join(s.workdir, "prewrapped", key[:12], fmt.Sprintf("%s-%s.wrap.tgz", ch.Name, ch.Version)), nil
+}
+
+// prewrapChart wraps a chart into the workdir, unless it was already wrapped
+// by a previous sync. Wraps of the chart made by previous syncs with a
+// different source chart or wrap settings are removed.
+func (s *Syncer) prewrapChart(ch *Chart, l log.SectionLogger) error {
+	dest, err := s.prewrapPath(ch)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := s.removeStaleWraps(dest); err != nil {
+		klog.Warningf("unable to remove stale wraps of %q chart: %v", ch.id(), err)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		klog.V(3).Infof("%q chart was already wrapped into %q", ch.id(), dest)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return errors.Trace(err)
+	}
+	workdir, err := os.MkdirTemp(filepath.Dir(dest), "wrap")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.RemoveAll(workdir)
+
+	// Wrap into a temporary file so interrupted wraps are not reused
+	tmp := filepath.Join(workdir, filepath.Base(dest))
+	var wrapped string
+	err = throttle.RetryTransfer(func() error {
+		var err error
+		wrapped, err = s.cli.src[ch.Source].Wrap(ch.TgzPath, tmp,
+			config.WithLogger(l), config.WithWorkDir(workdir),
+			config.WithContainerPlatforms(s.containerPlatforms), config.WithSkipArtifacts(s.skipArtifacts),
+			config.WithImageDiscovery(s.imageDiscovery),
+		)
+		return err
+	})
+	if err != nil {
+		return errors.Annotatef(err, "unable to wrap %q chart", ch.id())
+	}
+	// Local sources return the source bundle itself, which must not be moved
+	if wrapped != tmp {
+		if err := utils.CopyFile(tmp, wrapped); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(os.Rename(tmp, dest))
+}
+
+// removeStaleWraps removes the wraps of the same chart as dest stored under
+// other keys, and the key directories left empty
+func (s *Syncer) removeStaleWraps(dest string) error {
+	stale, err := filepath.Glob(filepath.Join(s.workdir, "prewrapped", "*", filepath.Base(dest)))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var errs error
+	for _, p := range stale {
+		if p == dest {
+			continue
+		}
+		klog.V(4).Infof("Removing stale wrap %q", p)
+		if err := os.Remove(p); err != nil {
+			errs = goerrors.Join(errs, errors.Trace(err))
+			continue
+		}
+		// Only empty directories are removed
+		_ = os.Remove(filepath.Dir(p))
+	}
+	return errs
+}
+
+// prewrapped returns the path of the chart wrapped by a previous sync outside
+// the allowed windows, or an empty string if there is none
+func (s *Syncer) prewrapped(ch *Chart) string {
+	dest, err := s.prewrapPath(ch)
+	if err != nil {
+		klog.Warningf("unable to look for %q chart wrapped by a previous sync: %v", ch.id(), err)
+		return ""
+	}
+	if _, err := os.Stat(dest); err != nil {
+		return ""
+	}
+	return dest
+}